@@ -0,0 +1,89 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// assumption is applied temporarily by SolveUnder to represent the
+// assumption that its subject is, or is not, selected.
+type assumption bool
+
+func (constraint assumption) String(subject Identifier) string {
+	if constraint {
+		return fmt.Sprintf("%s is assumed to be selected", subject)
+	}
+	return fmt.Sprintf("%s is assumed not to be selected", subject)
+}
+
+func (constraint assumption) Apply(_ *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	if constraint {
+		return lm.LitOf(subject)
+	}
+	return lm.LitOf(subject).Not()
+}
+
+func (constraint assumption) Order() []Identifier {
+	return nil
+}
+
+func (constraint assumption) Anchor() bool {
+	return bool(constraint)
+}
+
+func (constraint assumption) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return selected(subject) == bool(constraint)
+}
+
+// SolveUnder is like Solve, but additionally assumes that each
+// Variable identified by a key of assume is selected if the
+// corresponding value is true, and not selected otherwise. The
+// assumptions only last for the duration of the call, and nothing
+// needs to be encoded again to make them. Variables assumed to be
+// selected are treated as anchors, so their dependencies are chosen
+// according to preference as for any other anchor. If no solution
+// exists under the assumptions, the returned NotSatisfiable error
+// includes the assumptions that contribute to the conflict, as
+// constraints applied to the Variables they concern.
+//
+// Decomposition is not applied by SolveUnder, and assumptions can
+// never be relaxed by the correction sets suggested on failure.
+// Assumptions about Variables left out by WithPruning are rejected
+// with a PrunedVariable error.
+func (s *solver) SolveUnder(ctx context.Context, assume map[Identifier]bool) ([]Variable, error) {
+	for id := range assume {
+		if _, ok := s.pruned[id]; ok {
+			return nil, PrunedVariable(id)
+		}
+		if m, ok := s.litMap.lits[id]; !ok || !s.litMap.Present(m) {
+			return nil, fmt.Errorf("no variable with identifier %q", id)
+		}
+	}
+
+	// Apply the assumptions in input order, so that Variables
+	// assumed to be selected are anchored in input order.
+	var ids []Identifier
+	for _, v := range s.litMap.inorder {
+		value, ok := assume[v.Identifier()]
+		if !ok {
+			continue
+		}
+		m := s.litMap.assume(v, value)
+		if value {
+			s.hypotheses = append(s.hypotheses, m)
+		}
+		ids = append(ids, v.Identifier())
+	}
+	decompose := s.decompose
+	defer func() {
+		s.litMap.unassume(ids)
+		s.hypotheses = s.hypotheses[:0]
+		s.decompose = decompose
+	}()
+	s.decompose = false
+
+	return s.Solve(ctx)
+}
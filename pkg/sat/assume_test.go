@@ -0,0 +1,144 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveUnder(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("b", "c")),
+		variable("b"),
+		variable("c"),
+		variable("d", Dependency("e", "f")),
+		variable("e", Conflict("b")),
+		variable("f"),
+	}
+	s, err := NewSolver(WithInput(input), WithMinimalConflicts())
+	if !assert.NoError(err) {
+		return
+	}
+
+	// Would the problem still be satisfiable if b were
+	// prohibited?
+	installed, err := s.SolveUnder(context.TODO(), map[Identifier]bool{"b": false})
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "c"}, identifiers(installed))
+
+	// Can d be added? Its dependencies are resolved according to
+	// preference, as for any other anchor.
+	installed, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"d": true})
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "b", "d", "f"}, identifiers(installed))
+
+	// What if neither b nor c could be selected?
+	_, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"b": false, "c": false})
+	var conflicts NotSatisfiable
+	if assert.True(errors.As(err, &conflicts)) {
+		assert.ElementsMatch(NotSatisfiable{
+			{Variable: input[0], Constraint: Mandatory()},
+			{Variable: input[0], Constraint: Dependency("b", "c")},
+			{Variable: input[1], Constraint: assumption(false)},
+			{Variable: input[2], Constraint: assumption(false)},
+		}, conflicts)
+		assert.Contains(err.Error(), "b is assumed not to be selected")
+	}
+
+	// Assumptions do not outlive the call.
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "b"}, identifiers(installed))
+
+	_, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"missing": true})
+	assert.EqualError(err, `no variable with identifier "missing"`)
+}
+
+func TestSolveUnderPruned(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithPruning(), WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b", Dependency("c")),
+		variable("c"),
+	}))
+	assert.NoError(err)
+	_, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"b": true})
+	assert.Equal(PrunedVariable("b"), err)
+
+	_, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"missing": true})
+	assert.EqualError(err, `no variable with identifier "missing"`)
+}
+
+func TestSolveUnderSuggestions(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("b")),
+		variable("b"),
+	}
+	s, err := NewSolver(WithInput(input), WithSuggestions(4))
+	if !assert.NoError(err) {
+		return
+	}
+	_, err = s.SolveUnder(context.TODO(), map[Identifier]bool{"b": false})
+	var correctable Correctable
+	if assert.True(errors.As(err, &correctable)) {
+		// The assumption itself is never relaxed.
+		assert.ElementsMatch([]CorrectionSet{
+			{{Variable: input[0], Constraint: Mandatory()}},
+			{{Variable: input[0], Constraint: Dependency("b")}},
+		}, correctable.Corrections)
+	}
+}
+
+func TestSolveUnderMatchesSolve(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for i := 0; i < 200; i++ {
+		vars := randomVariables(r, 1+r.Intn(10))
+		assume := make(map[Identifier]bool)
+		for _, v := range vars {
+			if r.Intn(4) == 0 {
+				assume[v.Identifier()] = r.Intn(2) == 0
+			}
+		}
+
+		// Solving under assumptions is equivalent to solving
+		// with the corresponding constraints applied.
+		constrained := make([]Variable, len(vars))
+		for i, v := range vars {
+			cs := v.Constraints()
+			if value, ok := assume[v.Identifier()]; ok {
+				if value {
+					cs = append(cs[:len(cs):len(cs)], Mandatory())
+				} else {
+					cs = append(cs[:len(cs):len(cs)], Prohibited())
+				}
+			}
+			constrained[i] = variable(v.Identifier(), cs...)
+		}
+		s, err := NewSolver(WithInput(constrained))
+		if !assert.NoError(t, err) {
+			return
+		}
+		expected, expectedErr := s.Solve(context.TODO())
+
+		s, err = NewSolver(WithInput(vars))
+		if !assert.NoError(t, err) {
+			return
+		}
+		actual, err := s.SolveUnder(context.TODO(), assume)
+		if expectedErr != nil {
+			assert.True(t, errors.As(err, &NotSatisfiable{}), "expected %v under %v to be unsatisfiable, got %v", identifiers(vars), assume, err)
+			continue
+		}
+		if assert.NoError(t, err) {
+			assert.Equal(t, identifiers(expected), identifiers(actual))
+		}
+	}
+}
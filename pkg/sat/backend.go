@@ -0,0 +1,64 @@
+package sat
+
+import (
+	"github.com/go-air/gini/inter"
+	"github.com/go-air/gini/z"
+)
+
+// Backend is the interface to the incremental SAT solver underlying a
+// Solver. The default Backend is gini. A Backend that also implements
+// inter.GoSolvable can be interrupted while solving when the Context
+// passed to Solve is cancelled; others are only interrupted between
+// calls to Solve.
+type Backend interface {
+	inter.Adder
+	inter.Assumable
+	inter.Model
+	Test(dst []z.Lit) (result int, out []z.Lit)
+	Untest() int
+	Solve() int
+}
+
+// countingBackend counts the clauses, tests and solves requested of
+// the wrapped Backend.
+type countingBackend struct {
+	Backend
+	stats *Stats
+}
+
+// countingGoBackend is a countingBackend for a Backend that
+// implements inter.GoSolvable.
+type countingGoBackend struct {
+	*countingBackend
+	gs inter.GoSolvable
+}
+
+func newCountingBackend(b Backend, stats *Stats) Backend {
+	c := &countingBackend{Backend: b, stats: stats}
+	if gs, ok := b.(inter.GoSolvable); ok {
+		return countingGoBackend{countingBackend: c, gs: gs}
+	}
+	return c
+}
+
+func (b *countingBackend) Add(m z.Lit) {
+	if m == z.LitNull {
+		b.stats.Clauses++
+	}
+	b.Backend.Add(m)
+}
+
+func (b *countingBackend) Test(dst []z.Lit) (int, []z.Lit) {
+	b.stats.Tests++
+	return b.Backend.Test(dst)
+}
+
+func (b *countingBackend) Solve() int {
+	b.stats.Solves++
+	return b.Backend.Solve()
+}
+
+func (b countingGoBackend) GoSolve() inter.Solve {
+	b.stats.Solves++
+	return b.gs.GoSolve()
+}
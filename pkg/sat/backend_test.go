@@ -0,0 +1,104 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+// solutionKeys returns a sorted, comparable representation of a set
+// of solutions.
+func solutionKeys(solutions [][]Variable) []string {
+	keys := make([]string, len(solutions))
+	for i, solution := range solutions {
+		ids := make([]string, len(solution))
+		for j, v := range solution {
+			ids[j] = string(v.Identifier())
+		}
+		sort.Strings(ids)
+		keys[i] = strings.Join(ids, ",")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestBackendDifferential(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		vars := randomVariables(r, 1+r.Intn(7))
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			reference, err := NewSolver(WithInput(vars), WithBackend(NewDPLLBackend()))
+			assert.NoError(err)
+			subject, err := NewSolver(WithInput(vars))
+			assert.NoError(err)
+
+			expected, expectedErr := reference.Solve(context.TODO())
+			actual, actualErr := subject.Solve(context.TODO())
+			assert.Equal(errors.As(expectedErr, &NotSatisfiable{}), errors.As(actualErr, &NotSatisfiable{}), "%#v: %v, %v", vars, expectedErr, actualErr)
+			assert.Equal(identifiers(expected), identifiers(actual), "%#v", vars)
+
+			expectedAll, _ := reference.SolveAll(context.TODO(), 0)
+			actualAll, _ := subject.SolveAll(context.TODO(), 0)
+			assert.Equal(solutionKeys(expectedAll), solutionKeys(actualAll), "%#v", vars)
+
+			expectedOptimal, _ := reference.SolveAllOptimal(context.TODO(), 0)
+			actualOptimal, _ := subject.SolveAllOptimal(context.TODO(), 0)
+			assert.Equal(solutionKeys(expectedOptimal), solutionKeys(actualOptimal), "%#v", vars)
+		})
+	}
+}
+
+func TestDPLLBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b, c := z.Var(1).Pos(), z.Var(2).Pos(), z.Var(3).Pos()
+	g := NewDPLLBackend()
+	// a implies b, b implies not c
+	for _, m := range []z.Lit{a.Not(), b, z.LitNull, b.Not(), c.Not(), z.LitNull} {
+		g.Add(m)
+	}
+
+	g.Assume(a)
+	result, _ := g.Test(nil)
+	assert.Equal(satisfiable, result)
+	assert.True(g.Value(b))
+	assert.False(g.Value(c))
+
+	g.Assume(c)
+	assert.Equal(unsatisfiable, g.Solve())
+	assert.ElementsMatch([]z.Lit{a, c}, g.Why(nil))
+
+	// The assumption of c was forgotten.
+	assert.Equal(satisfiable, g.Solve())
+
+	assert.Equal(unknown, g.Untest())
+	g.Assume(c)
+	result, _ = g.Test(nil)
+	assert.Equal(satisfiable, result)
+	assert.False(g.Value(a))
+	assert.Equal(unknown, g.Untest())
+}
+
+func TestDPLLBackendStats(t *testing.T) {
+	assert := assert.New(t)
+
+	var stats Stats
+	s, err := NewSolver(WithStats(&stats), WithBackend(NewDPLLBackend()), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("b")),
+		variable("b"),
+	}))
+	assert.NoError(err)
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "b"}, identifiers(installed))
+	assert.Greater(stats.Clauses, 0)
+}
@@ -2,6 +2,7 @@ package sat
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"strconv"
 	"testing"
@@ -84,3 +85,188 @@ func BenchmarkNewInput(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkSessionSolve(b *testing.B) {
+	s, err := NewSession(WithInput(BenchmarkInput))
+	if err != nil {
+		b.Fatalf("failed to initialize session: %s", err)
+	}
+	if _, err := s.Solve(context.Background()); err != nil {
+		b.Fatalf("failed to solve: %s", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := BenchmarkInput[i%len(BenchmarkInput)]
+		s.Remove(v.Identifier())
+		if err := s.Add(v); err != nil {
+			b.Fatalf("failed to add variable: %s", err)
+		}
+		_, err = s.Solve(context.Background())
+		if err != nil {
+			b.Fatalf("failed to solve: %s", err)
+		}
+	}
+}
+
+// catalogInput returns Variables shaped like a package catalog:
+// each package has many versions, at most one of which may be
+// selected, and each version depends on one of a few of the newest
+// versions of some other packages, preferring newer versions. A
+// handful of packages are required.
+func catalogInput(packages, versions int) []Variable {
+	const (
+		seed        = 25
+		nRequired   = 4
+		nDependency = 3
+		nRange      = 4
+	)
+	r := rand.New(rand.NewSource(seed))
+
+	id := func(p, v int) Identifier {
+		return Identifier(fmt.Sprintf("p%d-v%d", p, v))
+	}
+	newest := func(p, lo, n int) []Identifier {
+		var ids []Identifier
+		for v := lo + n - 1; v >= lo; v-- {
+			if v < versions {
+				ids = append(ids, id(p, v))
+			}
+		}
+		return ids
+	}
+
+	var result []Variable
+	for p := 0; p < nRequired; p++ {
+		result = append(result, TestVariable{
+			identifier:  Identifier(fmt.Sprintf("required-p%d", p)),
+			constraints: []Constraint{Mandatory(), Dependency(newest(p, 0, versions)...)},
+		})
+	}
+	for p := 0; p < packages; p++ {
+		result = append(result, TestVariable{
+			identifier:  Identifier(fmt.Sprintf("unique-p%d", p)),
+			constraints: []Constraint{Mandatory(), AtMost(1, newest(p, 0, versions)...)},
+		})
+		for v := 0; v < versions; v++ {
+			var c []Constraint
+			for d := 0; d < nDependency; d++ {
+				if q := r.Intn(packages); q != p {
+					lo := versions - 1 - r.Intn(nRange)
+					c = append(c, Dependency(newest(q, lo, versions-lo)...))
+				}
+			}
+			result = append(result, TestVariable{
+				identifier:  id(p, v),
+				constraints: c,
+			})
+		}
+	}
+	return result
+}
+
+var cardinalityEncodings = []struct {
+	Name     string
+	Encoding CardinalityEncoding
+}{
+	{Name: "automatic", Encoding: AutomaticEncoding},
+	{Name: "sorting network", Encoding: SortingNetworkEncoding},
+	{Name: "sequential counter", Encoding: SequentialCounterEncoding},
+	{Name: "totalizer", Encoding: TotalizerEncoding},
+	{Name: "pairwise", Encoding: PairwiseEncoding},
+}
+
+func BenchmarkCardinalityEncoding(b *testing.B) {
+	for _, input := range []struct {
+		Name      string
+		Variables []Variable
+	}{
+		{Name: "benchmark input", Variables: BenchmarkInput},
+		{Name: "catalog 256x4", Variables: catalogInput(256, 4)},
+		{Name: "catalog 64x32", Variables: catalogInput(64, 32)},
+		{Name: "catalog 16x256", Variables: catalogInput(16, 256)},
+	} {
+		for _, e := range cardinalityEncodings {
+			b.Run(input.Name+"/"+e.Name, func(b *testing.B) {
+				var stats Stats
+				for i := 0; i < b.N; i++ {
+					s, err := NewSolver(WithInput(input.Variables), WithCardinalityEncoding(e.Encoding), WithStats(&stats))
+					if err != nil {
+						b.Fatalf("failed to initialize solver: %s", err)
+					}
+					if _, err := s.Solve(context.Background()); err != nil {
+						b.Fatalf("failed to solve: %s", err)
+					}
+				}
+				b.ReportMetric(float64(stats.Clauses), "clauses")
+			})
+		}
+	}
+}
+
+// penaltyInput returns Variables of which a few are required, each
+// depending on some of many others that are penalized, so that
+// minimizing the cost of a solution bounds the number of penalized
+// Variables selected. Another forced Variables each depend on a
+// penalized Variable of their own, which raises the minimum cost.
+func penaltyInput(penalized, required, forced int) []Variable {
+	const (
+		seed       = 25
+		candidates = 4
+	)
+	r := rand.New(rand.NewSource(seed))
+
+	id := func(i int) Identifier {
+		return Identifier(fmt.Sprintf("p%d", i))
+	}
+	var result []Variable
+	for i := 0; i < required; i++ {
+		var ids []Identifier
+		for j := 0; j < candidates; j++ {
+			ids = append(ids, id(r.Intn(penalized)))
+		}
+		result = append(result, TestVariable{
+			identifier:  Identifier(fmt.Sprintf("r%d", i)),
+			constraints: []Constraint{Mandatory(), Dependency(ids...)},
+		})
+	}
+	for i := 0; i < forced; i++ {
+		result = append(result, TestVariable{
+			identifier:  Identifier(fmt.Sprintf("f%d", i)),
+			constraints: []Constraint{Mandatory(), Dependency(id(i))},
+		})
+	}
+	for i := 0; i < penalized; i++ {
+		result = append(result, TestVariable{
+			identifier:  id(i),
+			constraints: []Constraint{Penalty(1)},
+		})
+	}
+	return result
+}
+
+func BenchmarkMinimize(b *testing.B) {
+	for _, input := range []struct {
+		Name      string
+		Variables []Variable
+	}{
+		{Name: "benchmark input", Variables: BenchmarkInput},
+		{Name: "penalties 1500", Variables: penaltyInput(1500, 8, 0)},
+		{Name: "forced penalties 1500", Variables: penaltyInput(1500, 8, 64)},
+	} {
+		for _, e := range cardinalityEncodings {
+			b.Run(input.Name+"/"+e.Name, func(b *testing.B) {
+				var stats Stats
+				for i := 0; i < b.N; i++ {
+					s, err := NewSolver(WithInput(input.Variables), WithCardinalityEncoding(e.Encoding), WithStats(&stats))
+					if err != nil {
+						b.Fatalf("failed to initialize solver: %s", err)
+					}
+					if _, err := s.Solve(context.Background()); err != nil {
+						b.Fatalf("failed to solve: %s", err)
+					}
+				}
+				b.ReportMetric(float64(stats.Clauses), "clauses")
+			})
+		}
+	}
+}
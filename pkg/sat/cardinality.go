@@ -0,0 +1,297 @@
+package sat
+
+import (
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// CardinalityEncoding determines how bounds on the number of true
+// literals among a group, such as those imposed by AtMost, AtLeast
+// and Exactly, and those used to minimize the cost and size of
+// solutions, are encoded for the underlying SAT solver.
+type CardinalityEncoding int
+
+const (
+	// AutomaticEncoding, the default, encodes bounds that are small
+	// compared to the size of the group, such as that of
+	// AtMost(1, ...) over many Variables, with a sequential
+	// counter, and larger bounds with a sorting network.
+	AutomaticEncoding CardinalityEncoding = iota
+	// SortingNetworkEncoding sorts the group with a network of
+	// O(n log² n) comparators, independent of the bound.
+	SortingNetworkEncoding
+	// SequentialCounterEncoding counts the true literals of the
+	// group one at a time, using O(n·k) gates for bounds up to k.
+	SequentialCounterEncoding
+	// TotalizerEncoding counts the true literals of the group by
+	// recursively adding the counts of each half, using O(n·k)
+	// gates for bounds up to k.
+	TotalizerEncoding
+	// PairwiseEncoding forbids each pair of literals in the group
+	// from being true together, using O(n²) gates and no counter
+	// at all. It only applies to bounds of at most one; other
+	// bounds use SequentialCounterEncoding.
+	PairwiseEncoding
+)
+
+// counter provides literals that are true if and only if the number
+// of true literals among a group satisfies a bound. Bounds outside of
+// [0, N()] yield constant literals.
+type counter interface {
+	N() int
+	Leq(k int) z.Lit
+	Geq(k int) z.Lit
+}
+
+var _ counter = &logic.CardSort{}
+
+// counter returns a counter over ms that encodes bounds using the
+// receiver. Each encoding constructs the literals for a bound only
+// when it is first requested.
+func (e CardinalityEncoding) counter(c *logic.C, ms []z.Lit) counter {
+	switch e {
+	case SortingNetworkEncoding:
+		return c.CardSort(ms)
+	case SequentialCounterEncoding:
+		return newSequentialCounter(c, ms)
+	case TotalizerEncoding:
+		return newTotalizer(c, ms)
+	case PairwiseEncoding:
+		return &pairwise{c: c, ms: ms}
+	}
+	return &hybrid{c: c, ms: ms, limit: sequentialLimit(len(ms))}
+}
+
+// sequentialLimit returns the largest bound on the number of true
+// literals among a group of n for which a sequential counter is
+// expected to produce a smaller circuit than a sorting network.
+func sequentialLimit(n int) int {
+	// A sequential counter needs about 2n gates for each bound,
+	// while a sorting network needs about n log² n / 4
+	// comparators of two gates each, whatever the bound. The
+	// constant is measured rather than derived. PairwiseEncoding
+	// is never smaller than a sequential counter, since it needs
+	// a gate for each pair.
+	lg := 0
+	for 1<<lg < n {
+		lg++
+	}
+	return lg * lg / 7
+}
+
+// hybrid encodes bounds up to limit with a sequential counter and
+// larger bounds with a sorting network, which is only constructed if
+// such a bound is requested.
+type hybrid struct {
+	c      *logic.C
+	ms     []z.Lit
+	limit  int
+	small  *sequentialCounter
+	sorted *logic.CardSort
+}
+
+func (h *hybrid) N() int {
+	return len(h.ms)
+}
+
+func (h *hybrid) Leq(k int) z.Lit {
+	if k <= h.limit || k >= len(h.ms) {
+		return h.sequential().Leq(k)
+	}
+	return h.network().Leq(k)
+}
+
+func (h *hybrid) Geq(k int) z.Lit {
+	return h.Leq(k - 1).Not()
+}
+
+func (h *hybrid) sequential() *sequentialCounter {
+	if h.small == nil {
+		h.small = newSequentialCounter(h.c, h.ms)
+	}
+	return h.small
+}
+
+func (h *hybrid) network() *logic.CardSort {
+	if h.sorted == nil {
+		h.sorted = h.c.CardSort(h.ms)
+	}
+	return h.sorted
+}
+
+// sequentialCounter implements the sequential counter encoding of
+// Sinz, "Towards an Optimal CNF Encoding of Boolean Cardinality
+// Constraints" (2005). The literals for each bound are constructed
+// only when first needed.
+type sequentialCounter struct {
+	c  *logic.C
+	ms []z.Lit
+	// levels[j][i] is true if more than j of ms[:i+1] are true
+	levels [][]z.Lit
+}
+
+func newSequentialCounter(c *logic.C, ms []z.Lit) *sequentialCounter {
+	return &sequentialCounter{c: c, ms: ms}
+}
+
+func (s *sequentialCounter) N() int {
+	return len(s.ms)
+}
+
+func (s *sequentialCounter) Leq(k int) z.Lit {
+	return s.Geq(k + 1).Not()
+}
+
+func (s *sequentialCounter) Geq(k int) z.Lit {
+	if k <= 0 {
+		return s.c.T
+	}
+	if k > len(s.ms) {
+		return s.c.F
+	}
+	for j := len(s.levels); j < k; j++ {
+		level := make([]z.Lit, len(s.ms))
+		prev := s.c.F
+		for i, m := range s.ms {
+			carry := m
+			if j > 0 {
+				carry = s.c.F
+				if i > 0 {
+					carry = s.c.And(m, s.levels[j-1][i-1])
+				}
+			}
+			prev = s.c.Or(prev, carry)
+			level[i] = prev
+		}
+		s.levels = append(s.levels, level)
+	}
+	return s.levels[k-1][len(s.ms)-1]
+}
+
+// totalizer implements the totalizer encoding of Bailleux and
+// Boufkhad, "Efficient CNF Encoding of Boolean Cardinality
+// Constraints" (2003). The literals for each bound are constructed
+// only when first needed.
+type totalizer struct {
+	c    *logic.C
+	root *totalizerNode
+	n    int
+}
+
+// totalizerNode counts the true literals among the leaves below it.
+type totalizerNode struct {
+	left, right *totalizerNode
+	m           z.Lit
+	size        int
+	// geq[j] is true if more than j leaves below the node are
+	// true
+	geq []z.Lit
+}
+
+func newTotalizer(c *logic.C, ms []z.Lit) *totalizer {
+	var build func(ms []z.Lit) *totalizerNode
+	build = func(ms []z.Lit) *totalizerNode {
+		if len(ms) == 1 {
+			return &totalizerNode{m: ms[0], size: 1}
+		}
+		return &totalizerNode{
+			left:  build(ms[:len(ms)/2]),
+			right: build(ms[len(ms)/2:]),
+			size:  len(ms),
+		}
+	}
+	t := totalizer{c: c, n: len(ms)}
+	if len(ms) > 0 {
+		t.root = build(ms)
+	}
+	return &t
+}
+
+func (t *totalizer) N() int {
+	return t.n
+}
+
+func (t *totalizer) Leq(k int) z.Lit {
+	return t.Geq(k + 1).Not()
+}
+
+func (t *totalizer) Geq(k int) z.Lit {
+	return t.geq(t.root, k)
+}
+
+func (t *totalizer) geq(node *totalizerNode, k int) z.Lit {
+	if k <= 0 {
+		return t.c.T
+	}
+	if node == nil || k > node.size {
+		return t.c.F
+	}
+	if node.left == nil {
+		return node.m
+	}
+	for j := len(node.geq) + 1; j <= k; j++ {
+		// At least j leaves are true if, for some split of j,
+		// enough are true on each side.
+		out := t.c.F
+		for a := 0; a <= j; a++ {
+			out = t.c.Or(out, t.c.And(t.geq(node.left, a), t.geq(node.right, j-a)))
+		}
+		node.geq = append(node.geq, out)
+	}
+	return node.geq[k-1]
+}
+
+// pairwise encodes bounds of at most one by ruling out each pair of
+// true literals. Other bounds fall back to a sequential counter.
+type pairwise struct {
+	c        *logic.C
+	ms       []z.Lit
+	fallback *sequentialCounter
+}
+
+func (p *pairwise) N() int {
+	return len(p.ms)
+}
+
+func (p *pairwise) Leq(k int) z.Lit {
+	switch {
+	case k < 0:
+		return p.c.F
+	case k >= len(p.ms):
+		return p.c.T
+	case k == 0:
+		m := p.c.T
+		for _, each := range p.ms {
+			m = p.c.And(m, each.Not())
+		}
+		return m
+	case k == 1:
+		m := p.c.T
+		for i, a := range p.ms {
+			for _, b := range p.ms[i+1:] {
+				m = p.c.And(m, p.c.And(a, b).Not())
+			}
+		}
+		return m
+	}
+	return p.sequential().Leq(k)
+}
+
+func (p *pairwise) Geq(k int) z.Lit {
+	switch {
+	case k <= 0:
+		return p.c.T
+	case k > len(p.ms):
+		return p.c.F
+	case k == 1:
+		return p.c.Ors(p.ms...)
+	}
+	return p.sequential().Geq(k)
+}
+
+func (p *pairwise) sequential() *sequentialCounter {
+	if p.fallback == nil {
+		p.fallback = newSequentialCounter(p.c, p.ms)
+	}
+	return p.fallback
+}
@@ -0,0 +1,134 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+var encodings = []CardinalityEncoding{
+	AutomaticEncoding,
+	SortingNetworkEncoding,
+	SequentialCounterEncoding,
+	TotalizerEncoding,
+	PairwiseEncoding,
+}
+
+func TestCounter(t *testing.T) {
+	r := rand.New(rand.NewSource(25))
+	for i := 0; i < 200; i++ {
+		for _, e := range encodings {
+			n := r.Intn(7)
+			c := logic.NewC()
+			ms := make([]z.Lit, n)
+			for j := range ms {
+				ms[j] = c.Lit()
+				if j > 0 && r.Intn(5) == 0 {
+					// Literals may appear more than once,
+					// and in both polarities.
+					ms[j] = ms[r.Intn(j)]
+					if r.Intn(2) == 0 {
+						ms[j] = ms[j].Not()
+					}
+				}
+			}
+			cs := e.counter(c, ms)
+			assert.Equal(t, n, cs.N())
+			var leq, geq []z.Lit
+			for k := -1; k <= n+1; k++ {
+				leq = append(leq, cs.Leq(k))
+				geq = append(geq, cs.Geq(k))
+			}
+
+			inputs := c.InPos(nil)
+			for mask := 0; mask < 1<<len(inputs); mask++ {
+				vs := make([]bool, c.Len())
+				for b, in := range inputs {
+					vs[in] = mask&(1<<b) != 0
+				}
+				c.Eval(vs)
+				value := func(m z.Lit) bool {
+					return vs[m.Var()] == m.IsPos()
+				}
+				var count int
+				for _, m := range ms {
+					if value(m) {
+						count++
+					}
+				}
+				for j := range leq {
+					k := j - 1
+					if value(leq[j]) != (count <= k) {
+						t.Fatalf("encoding %d of %d literals: Leq(%d) is %t for count %d", e, n, k, value(leq[j]), count)
+					}
+					if value(geq[j]) != (count >= k) {
+						t.Fatalf("encoding %d of %d literals: Geq(%d) is %t for count %d", e, n, k, value(geq[j]), count)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSequentialLimit(t *testing.T) {
+	for _, tt := range []struct {
+		N        int
+		Expected int
+	}{
+		{N: 0, Expected: 0},
+		{N: 4, Expected: 0},
+		{N: 8, Expected: 1},
+		{N: 16, Expected: 2},
+		{N: 64, Expected: 5},
+		{N: 256, Expected: 9},
+		{N: 512, Expected: 11},
+	} {
+		assert.Equal(t, tt.Expected, sequentialLimit(tt.N), "group of %d", tt.N)
+	}
+}
+
+func TestCardinalityEncodingsAgree(t *testing.T) {
+	r := rand.New(rand.NewSource(25))
+	for i := 0; i < 200; i++ {
+		vars := randomVariables(r, 1+r.Intn(12))
+		// Add bounds other than one to some Variables.
+		for j, v := range vars {
+			if r.Intn(4) != 0 {
+				continue
+			}
+			var ids []Identifier
+			for _, u := range vars {
+				if r.Intn(2) == 0 {
+					ids = append(ids, u.Identifier())
+				}
+			}
+			n := r.Intn(len(ids) + 1)
+			c := []Constraint{AtMost(n, ids...), AtLeast(n, ids...), Exactly(n, ids...)}[r.Intn(3)]
+			vars[j] = variable(v.Identifier(), append(v.Constraints(), c)...)
+		}
+
+		var outcomes []bool
+		for _, e := range encodings {
+			s, err := NewSolver(WithInput(vars), WithCardinalityEncoding(e))
+			if !assert.NoError(t, err) {
+				return
+			}
+			installed, err := s.Solve(context.Background())
+			if err != nil && !errors.As(err, &NotSatisfiable{}) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil {
+				assert.Empty(t, Verify(vars, installed))
+			}
+			outcomes = append(outcomes, err == nil)
+		}
+		for _, outcome := range outcomes {
+			assert.Equal(t, outcomes[0], outcome)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package sat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// The Constraints in this file compose other Constraints into
+// arbitrary boolean expressions. Operands are applied to the same
+// subject as the composed Constraint. Composed Constraints do not
+// contribute to search preferences: their Order is always empty.
+// When evaluated, an operand that does not implement Evaluator is
+// treated as if it did not hold, and Verify reports the composed
+// Constraint as unverifiable.
+
+// applyOperand returns the literal of an operand Constraint, treating
+// an operand without a useful representation in the SAT inputs as
+// one that always holds.
+func applyOperand(c *logic.C, lm *LitMapping, subject Identifier, operand Constraint) z.Lit {
+	if m := operand.Apply(c, lm, subject); m != z.LitNull {
+		return m
+	}
+	return c.T
+}
+
+// describe returns a description of an operand Constraint, without
+// any leading reference to the subject, suitable for nesting within
+// the description of another composed Constraint.
+func describe(subject Identifier, operand Constraint) string {
+	switch operand := operand.(type) {
+	case selected:
+		return operand.describe()
+	case not:
+		return operand.describe(subject)
+	case allOf:
+		return fmt.Sprintf("(%s)", operand.describe(subject))
+	case anyOf:
+		return fmt.Sprintf("(%s)", operand.describe(subject))
+	case implies:
+		return fmt.Sprintf("(%s)", operand.describe(subject))
+	}
+	return operand.String(subject)
+}
+
+type selected Identifier
+
+func (constraint selected) String(subject Identifier) string {
+	return fmt.Sprintf("%s requires that %s", subject, constraint.describe())
+}
+
+func (constraint selected) describe() string {
+	return fmt.Sprintf("%s is selected", Identifier(constraint))
+}
+
+func (constraint selected) Apply(_ *logic.C, lm *LitMapping, _ Identifier) z.Lit {
+	return lm.LitOf(Identifier(constraint))
+}
+
+func (constraint selected) Order() []Identifier {
+	return nil
+}
+
+func (constraint selected) Anchor() bool {
+	return false
+}
+
+func (constraint selected) Evaluate(_ Identifier, selected func(Identifier) bool) bool {
+	return selected(Identifier(constraint))
+}
+
+// Selected returns a Constraint that holds only for solutions
+// containing the Variable identified by the given Identifier. It is
+// intended to be used as an operand of Not, All, Any and Implies.
+func Selected(id Identifier) Constraint {
+	return selected(id)
+}
+
+type not struct {
+	operand Constraint
+}
+
+func (constraint not) String(subject Identifier) string {
+	return fmt.Sprintf("%s requires that %s", subject, constraint.describe(subject))
+}
+
+func (constraint not) describe(subject Identifier) string {
+	return fmt.Sprintf("not (%s)", describe(subject, constraint.operand))
+}
+
+func (constraint not) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	return applyOperand(c, lm, subject, constraint.operand).Not()
+}
+
+func (constraint not) Order() []Identifier {
+	return nil
+}
+
+func (constraint not) Anchor() bool {
+	return false
+}
+
+func (constraint not) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	v, _ := constraint.evaluate(subject, selected)
+	return v
+}
+
+func (constraint not) evaluate(subject Identifier, selected func(Identifier) bool) (bool, bool) {
+	v, ok := evaluate(constraint.operand, subject, selected)
+	return !v, ok
+}
+
+// Not returns a Constraint that holds only when the given Constraint
+// does not.
+func Not(operand Constraint) Constraint {
+	return not{operand: operand}
+}
+
+type allOf []Constraint
+
+func (constraint allOf) String(subject Identifier) string {
+	if len(constraint) == 0 {
+		return fmt.Sprintf("%s has an empty conjunction", subject)
+	}
+	return fmt.Sprintf("%s requires that %s", subject, constraint.describe(subject))
+}
+
+func (constraint allOf) describe(subject Identifier) string {
+	s := make([]string, len(constraint))
+	for i, each := range constraint {
+		s[i] = describe(subject, each)
+	}
+	return strings.Join(s, " and ")
+}
+
+func (constraint allOf) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint))
+	for i, each := range constraint {
+		ms[i] = applyOperand(c, lm, subject, each)
+	}
+	return c.Ands(ms...)
+}
+
+func (constraint allOf) Order() []Identifier {
+	return nil
+}
+
+// Anchor returns true if any operand is an anchor, since every
+// operand must hold.
+func (constraint allOf) Anchor() bool {
+	for _, each := range constraint {
+		if each.Anchor() {
+			return true
+		}
+	}
+	return false
+}
+
+func (constraint allOf) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	v, _ := constraint.evaluate(subject, selected)
+	return v
+}
+
+func (constraint allOf) evaluate(subject Identifier, selected func(Identifier) bool) (bool, bool) {
+	for _, each := range constraint {
+		if v, ok := evaluate(each, subject, selected); !ok || !v {
+			return false, ok
+		}
+	}
+	return true, true
+}
+
+// All returns a Constraint that holds only when every one of the
+// given Constraints holds. With no operands, it always holds.
+func All(operands ...Constraint) Constraint {
+	return allOf(operands)
+}
+
+type anyOf []Constraint
+
+func (constraint anyOf) String(subject Identifier) string {
+	if len(constraint) == 0 {
+		return fmt.Sprintf("%s has an empty disjunction", subject)
+	}
+	return fmt.Sprintf("%s requires that %s", subject, constraint.describe(subject))
+}
+
+func (constraint anyOf) describe(subject Identifier) string {
+	s := make([]string, len(constraint))
+	for i, each := range constraint {
+		s[i] = describe(subject, each)
+	}
+	return strings.Join(s, " or ")
+}
+
+func (constraint anyOf) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint))
+	for i, each := range constraint {
+		ms[i] = applyOperand(c, lm, subject, each)
+	}
+	return c.Ors(ms...)
+}
+
+func (constraint anyOf) Order() []Identifier {
+	return nil
+}
+
+func (constraint anyOf) Anchor() bool {
+	return false
+}
+
+func (constraint anyOf) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	v, _ := constraint.evaluate(subject, selected)
+	return v
+}
+
+func (constraint anyOf) evaluate(subject Identifier, selected func(Identifier) bool) (bool, bool) {
+	known := true
+	for _, each := range constraint {
+		v, ok := evaluate(each, subject, selected)
+		if ok && v {
+			return true, true
+		}
+		known = known && ok
+	}
+	return false, known
+}
+
+// Any returns a Constraint that holds when at least one of the given
+// Constraints holds. With no operands, it never holds.
+func Any(operands ...Constraint) Constraint {
+	return anyOf(operands)
+}
+
+type implies struct {
+	antecedent, consequent Constraint
+}
+
+func (constraint implies) String(subject Identifier) string {
+	return fmt.Sprintf("%s requires that %s", subject, constraint.describe(subject))
+}
+
+func (constraint implies) describe(subject Identifier) string {
+	return fmt.Sprintf("if %s then %s", describe(subject, constraint.antecedent), describe(subject, constraint.consequent))
+}
+
+func (constraint implies) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	return c.Implies(
+		applyOperand(c, lm, subject, constraint.antecedent),
+		applyOperand(c, lm, subject, constraint.consequent),
+	)
+}
+
+func (constraint implies) Order() []Identifier {
+	return nil
+}
+
+func (constraint implies) Anchor() bool {
+	return false
+}
+
+func (constraint implies) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	v, _ := constraint.evaluate(subject, selected)
+	return v
+}
+
+func (constraint implies) evaluate(subject Identifier, selected func(Identifier) bool) (bool, bool) {
+	antecedent, ok := evaluate(constraint.antecedent, subject, selected)
+	if ok && !antecedent {
+		return true, true
+	}
+	consequent, cok := evaluate(constraint.consequent, subject, selected)
+	if cok && consequent {
+		return true, true
+	}
+	return false, ok && cok
+}
+
+// Implies returns a Constraint that holds unless the antecedent
+// Constraint holds and the consequent Constraint does not.
+func Implies(antecedent, consequent Constraint) Constraint {
+	return implies{
+		antecedent: antecedent,
+		consequent: consequent,
+	}
+}
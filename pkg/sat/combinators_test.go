@@ -0,0 +1,162 @@
+package sat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinatorString(t *testing.T) {
+	for _, tt := range []struct {
+		Name       string
+		Constraint Constraint
+		String     string
+	}{
+		{
+			Name:       "selected",
+			Constraint: Selected("a"),
+			String:     "x requires that a is selected",
+		},
+		{
+			Name:       "not",
+			Constraint: Not(Selected("a")),
+			String:     "x requires that not (a is selected)",
+		},
+		{
+			Name:       "all",
+			Constraint: All(Selected("a"), Selected("b")),
+			String:     "x requires that a is selected and b is selected",
+		},
+		{
+			Name:       "any",
+			Constraint: Any(Selected("a"), Selected("b")),
+			String:     "x requires that a is selected or b is selected",
+		},
+		{
+			Name:       "implies",
+			Constraint: Implies(Selected("a"), Selected("b")),
+			String:     "x requires that if a is selected then b is selected",
+		},
+		{
+			Name:       "nested",
+			Constraint: Implies(Selected("a"), Any(Selected("b"), All(Selected("c"), Not(Selected("d"))))),
+			String:     "x requires that if a is selected then (b is selected or (c is selected and not (d is selected)))",
+		},
+		{
+			Name:       "built-in operand",
+			Constraint: Any(Mandatory(), Conflict("a")),
+			String:     "x requires that x is mandatory or x conflicts with a",
+		},
+		{
+			Name:       "empty all",
+			Constraint: All(),
+			String:     "x has an empty conjunction",
+		},
+		{
+			Name:       "empty any",
+			Constraint: Any(),
+			String:     "x has an empty disjunction",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.String, tt.Constraint.String("x"))
+		})
+	}
+}
+
+func TestCombinatorAnchor(t *testing.T) {
+	assert.True(t, All(Dependency("a"), Mandatory()).Anchor())
+	assert.False(t, All(Dependency("a")).Anchor())
+	assert.False(t, Any(Mandatory(), Dependency("a")).Anchor())
+	assert.False(t, Not(Prohibited()).Anchor())
+}
+
+func TestSolveCombinators(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Installed []Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "implication is enforced",
+			Variables: []Variable{
+				variable("x", Mandatory(), Implies(Selected("a"), Selected("b"))),
+				variable("a", Mandatory()),
+				variable("b"),
+			},
+			Installed: []Identifier{"x", "a", "b"},
+		},
+		{
+			Name: "implication without antecedent",
+			Variables: []Variable{
+				variable("x", Mandatory(), Implies(Selected("a"), Selected("b"))),
+				variable("a"),
+				variable("b"),
+			},
+			Installed: []Identifier{"x"},
+		},
+		{
+			Name: "nested expression",
+			Variables: []Variable{
+				variable("x", Mandatory(), Implies(Selected("a"), Any(Selected("b"), All(Selected("c"), Not(Selected("d")))))),
+				variable("a", Mandatory()),
+				variable("b", Prohibited()),
+				variable("c"),
+				variable("d"),
+			},
+			Installed: []Identifier{"x", "a", "c"},
+		},
+		{
+			Name: "all with mandatory operand anchors subject",
+			Variables: []Variable{
+				variable("x", All(Mandatory(), Dependency("a"))),
+				variable("a"),
+			},
+			Installed: []Identifier{"x", "a"},
+		},
+		{
+			Name: "negated expression in conflict",
+			Variables: []Variable{
+				variable("x", Mandatory(), Not(Any(Selected("a"), Selected("b")))),
+				variable("a"),
+				variable("b", Mandatory()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("x", Mandatory(), Not(Any(Selected("a"), Selected("b")))),
+					Constraint: Not(Any(Selected("a"), Selected("b"))),
+				},
+				{
+					Variable:   variable("b", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := NewSolver(WithInput(tt.Variables))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.Solve(context.TODO())
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+			if tt.Error == nil {
+				assert.NoError(err)
+				return
+			}
+			assert.ElementsMatch(tt.Error, err)
+		})
+	}
+}
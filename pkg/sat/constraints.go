@@ -17,6 +17,16 @@ type Constraint interface {
 	Anchor() bool
 }
 
+// SoftConstraint is implemented by Constraints that may be violated
+// at a cost. The literal returned by Apply is not required to hold;
+// instead, each solution pays Weight for every SoftConstraint it
+// violates, and Solve only returns solutions of minimal total cost.
+// SoftConstraints with a non-positive Weight have no effect.
+type SoftConstraint interface {
+	Constraint
+	Weight() int
+}
+
 // zeroConstraint is returned by ConstraintOf in error cases.
 type zeroConstraint struct{}
 
@@ -38,6 +48,10 @@ func (zeroConstraint) Anchor() bool {
 	return false
 }
 
+func (zeroConstraint) Evaluate(_ Identifier, _ func(Identifier) bool) bool {
+	return true
+}
+
 // AppliedConstraint values compose a single Constraint with the
 // Variable it applies to.
 type AppliedConstraint struct {
@@ -69,6 +83,10 @@ func (constraint mandatory) Anchor() bool {
 	return true
 }
 
+func (constraint mandatory) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return selected(subject)
+}
+
 // Mandatory returns a Constraint that will permit only solutions that
 // contain a particular Variable.
 func Mandatory() Constraint {
@@ -93,6 +111,10 @@ func (constraint prohibited) Anchor() bool {
 	return false
 }
 
+func (constraint prohibited) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return !selected(subject)
+}
+
 // Prohibited returns a Constraint that will reject any solution that
 // contains a particular Variable. Callers may also decide to omit
 // an Variable from input to Solve rather than Apply such a
@@ -130,6 +152,18 @@ func (constraint dependency) Anchor() bool {
 	return false
 }
 
+func (constraint dependency) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	if !selected(subject) {
+		return true
+	}
+	for _, each := range constraint {
+		if selected(each) {
+			return true
+		}
+	}
+	return false
+}
+
 // Dependency returns a Constraint that will only permit solutions
 // containing a given Variable on the condition that at least one
 // of the Variables identified by the given Identifiers also
@@ -157,6 +191,10 @@ func (constraint conflict) Anchor() bool {
 	return false
 }
 
+func (constraint conflict) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return !selected(subject) || !selected(Identifier(constraint))
+}
+
 // Conflict returns a Constraint that will permit solutions containing
 // either the constrained Variable, the Variable identified by
 // the given Identifier, or neither, but not both.
@@ -182,7 +220,7 @@ func (constraint leq) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Li
 	for i, each := range constraint.ids {
 		ms[i] = lm.LitOf(each)
 	}
-	return c.CardSort(ms).Leq(constraint.n)
+	return lm.counter(c, ms).Leq(constraint.n)
 }
 
 func (constraint leq) Order() []Identifier {
@@ -193,6 +231,10 @@ func (constraint leq) Anchor() bool {
 	return false
 }
 
+func (constraint leq) Evaluate(_ Identifier, selected func(Identifier) bool) bool {
+	return count(constraint.ids, selected) <= constraint.n
+}
+
 // AtMost returns a Constraint that forbids solutions that contain
 // more than n of the Variables identified by the given
 // Identifiers.
@@ -202,3 +244,252 @@ func AtMost(n int, ids ...Identifier) Constraint {
 		n:   n,
 	}
 }
+
+type prefer int
+
+func (constraint prefer) String(subject Identifier) string {
+	return fmt.Sprintf("%s is preferred with weight %d", subject, int(constraint))
+}
+
+func (constraint prefer) Apply(_ *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(subject)
+}
+
+func (constraint prefer) Order() []Identifier {
+	return nil
+}
+
+func (constraint prefer) Anchor() bool {
+	return false
+}
+
+func (constraint prefer) Weight() int {
+	return int(constraint)
+}
+
+func (constraint prefer) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return selected(subject)
+}
+
+// Prefer returns a SoftConstraint that adds weight to the cost of
+// any solution that does not contain a particular Variable.
+func Prefer(weight int) Constraint {
+	return prefer(weight)
+}
+
+type penalty int
+
+func (constraint penalty) String(subject Identifier) string {
+	return fmt.Sprintf("%s is penalized with weight %d", subject, int(constraint))
+}
+
+func (constraint penalty) Apply(_ *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(subject).Not()
+}
+
+func (constraint penalty) Order() []Identifier {
+	return nil
+}
+
+func (constraint penalty) Anchor() bool {
+	return false
+}
+
+func (constraint penalty) Weight() int {
+	return int(constraint)
+}
+
+func (constraint penalty) Evaluate(subject Identifier, selected func(Identifier) bool) bool {
+	return !selected(subject)
+}
+
+// Penalty returns a SoftConstraint that adds weight to the cost of
+// any solution that contains a particular Variable.
+func Penalty(weight int) Constraint {
+	return penalty(weight)
+}
+
+type geq struct {
+	ids []Identifier
+	n   int
+}
+
+func (constraint geq) String(subject Identifier) string {
+	s := make([]string, len(constraint.ids))
+	for i, each := range constraint.ids {
+		s[i] = string(each)
+	}
+	return fmt.Sprintf("%s requires at least %d of %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint geq) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.ids))
+	for i, each := range constraint.ids {
+		ms[i] = lm.LitOf(each)
+	}
+	return lm.counter(c, ms).Geq(constraint.n)
+}
+
+func (constraint geq) Order() []Identifier {
+	return nil
+}
+
+func (constraint geq) Anchor() bool {
+	return false
+}
+
+func (constraint geq) Evaluate(_ Identifier, selected func(Identifier) bool) bool {
+	return count(constraint.ids, selected) >= constraint.n
+}
+
+// AtLeast returns a Constraint that forbids solutions that contain
+// fewer than n of the Variables identified by the given
+// Identifiers.
+func AtLeast(n int, ids ...Identifier) Constraint {
+	return geq{
+		ids: ids,
+		n:   n,
+	}
+}
+
+type between struct {
+	ids    []Identifier
+	lo, hi int
+}
+
+func (constraint between) String(subject Identifier) string {
+	s := make([]string, len(constraint.ids))
+	for i, each := range constraint.ids {
+		s[i] = string(each)
+	}
+	if constraint.lo == constraint.hi {
+		return fmt.Sprintf("%s requires exactly %d of %s", subject, constraint.lo, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s requires between %d and %d of %s", subject, constraint.lo, constraint.hi, strings.Join(s, ", "))
+}
+
+func (constraint between) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.ids))
+	for i, each := range constraint.ids {
+		ms[i] = lm.LitOf(each)
+	}
+	cs := lm.counter(c, ms)
+	return c.And(cs.Geq(constraint.lo), cs.Leq(constraint.hi))
+}
+
+func (constraint between) Order() []Identifier {
+	return nil
+}
+
+func (constraint between) Anchor() bool {
+	return false
+}
+
+func (constraint between) Evaluate(_ Identifier, selected func(Identifier) bool) bool {
+	n := count(constraint.ids, selected)
+	return n >= constraint.lo && n <= constraint.hi
+}
+
+// Exactly returns a Constraint that forbids solutions that do not
+// contain exactly n of the Variables identified by the given
+// Identifiers.
+func Exactly(n int, ids ...Identifier) Constraint {
+	return Between(n, n, ids...)
+}
+
+// Between returns a Constraint that forbids solutions that contain
+// fewer than lo or more than hi of the Variables identified by the
+// given Identifiers.
+func Between(lo, hi int, ids ...Identifier) Constraint {
+	return between{
+		ids: ids,
+		lo:  lo,
+		hi:  hi,
+	}
+}
+
+// Weighted pairs an Identifier with a weight, for use with
+// WeightedAtMost and WeightedAtLeast.
+type Weighted struct {
+	Identifier Identifier
+	Weight     int
+}
+
+type weightedSum struct {
+	terms   []Weighted
+	n       int
+	atLeast bool
+}
+
+func (constraint weightedSum) String(subject Identifier) string {
+	s := make([]string, len(constraint.terms))
+	for i, each := range constraint.terms {
+		s[i] = fmt.Sprintf("%s (weight %d)", each.Identifier, each.Weight)
+	}
+	if constraint.atLeast {
+		return fmt.Sprintf("%s requires a total weight of at least %d among %s", subject, constraint.n, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s permits a total weight of at most %d among %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint weightedSum) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.terms))
+	ws := make([]int, len(constraint.terms))
+	for i, each := range constraint.terms {
+		ms[i] = lm.LitOf(each.Identifier)
+		ws[i] = each.Weight
+		if constraint.atLeast {
+			// sum(w*m) >= n if and only if sum(-w*m) <= -n
+			ws[i] = -ws[i]
+		}
+	}
+	if constraint.atLeast {
+		return pbLeq(c, ms, ws, -constraint.n)
+	}
+	return pbLeq(c, ms, ws, constraint.n)
+}
+
+func (constraint weightedSum) Order() []Identifier {
+	return nil
+}
+
+func (constraint weightedSum) Anchor() bool {
+	return false
+}
+
+func (constraint weightedSum) Evaluate(_ Identifier, selected func(Identifier) bool) bool {
+	var total int
+	for _, each := range constraint.terms {
+		if selected(each.Identifier) {
+			total += each.Weight
+		}
+	}
+	if constraint.atLeast {
+		return total >= constraint.n
+	}
+	return total <= constraint.n
+}
+
+// WeightedAtMost returns a Constraint that forbids solutions in which
+// the weights of the Variables identified by the given terms add up
+// to more than n, for example to keep the resources requested by the
+// selected Variables within a budget. An Identifier that appears in
+// several terms contributes the sum of their weights.
+func WeightedAtMost(n int, terms ...Weighted) Constraint {
+	return weightedSum{
+		terms: terms,
+		n:     n,
+	}
+}
+
+// WeightedAtLeast returns a Constraint that forbids solutions in
+// which the weights of the Variables identified by the given terms
+// add up to less than n. An Identifier that appears in several terms
+// contributes the sum of their weights.
+func WeightedAtLeast(n int, terms ...Weighted) Constraint {
+	return weightedSum{
+		terms:   terms,
+		n:       n,
+		atLeast: true,
+	}
+}
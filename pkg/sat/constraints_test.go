@@ -31,9 +31,100 @@ func TestOrder(t *testing.T) {
 			Name:       "conflict",
 			Constraint: Conflict("a"),
 		},
+		{
+			Name:       "at most",
+			Constraint: AtMost(1, "a", "b"),
+		},
+		{
+			Name:       "at least",
+			Constraint: AtLeast(1, "a", "b"),
+		},
+		{
+			Name:       "exactly",
+			Constraint: Exactly(1, "a", "b"),
+		},
+		{
+			Name:       "between",
+			Constraint: Between(1, 2, "a", "b", "c"),
+		},
+		{
+			Name:       "prefer",
+			Constraint: Prefer(1),
+		},
+		{
+			Name:       "penalty",
+			Constraint: Penalty(1),
+		},
+		{
+			Name:       "weighted at most",
+			Constraint: WeightedAtMost(1, Weighted{Identifier: "a", Weight: 1}),
+		},
 	} {
 		t.Run(tt.Name, func(t *testing.T) {
 			assert.Equal(t, tt.Expected, tt.Constraint.Order())
 		})
 	}
 }
+
+func TestString(t *testing.T) {
+	for _, tt := range []struct {
+		Name       string
+		Constraint Constraint
+		String     string
+	}{
+		{
+			Name:       "at most",
+			Constraint: AtMost(1, "a", "b"),
+			String:     "x permits at most 1 of a, b",
+		},
+		{
+			Name:       "at least",
+			Constraint: AtLeast(2, "a", "b", "c"),
+			String:     "x requires at least 2 of a, b, c",
+		},
+		{
+			Name:       "exactly",
+			Constraint: Exactly(1, "a", "b"),
+			String:     "x requires exactly 1 of a, b",
+		},
+		{
+			Name:       "between",
+			Constraint: Between(1, 2, "a", "b", "c"),
+			String:     "x requires between 1 and 2 of a, b, c",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.String, tt.Constraint.String("x"))
+		})
+	}
+}
+
+func TestWeight(t *testing.T) {
+	for _, tt := range []struct {
+		Name       string
+		Constraint Constraint
+		Weight     int
+		String     string
+	}{
+		{
+			Name:       "prefer",
+			Constraint: Prefer(3),
+			Weight:     3,
+			String:     "a is preferred with weight 3",
+		},
+		{
+			Name:       "penalty",
+			Constraint: Penalty(2),
+			Weight:     2,
+			String:     "a is penalized with weight 2",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			sc, ok := tt.Constraint.(SoftConstraint)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.Weight, sc.Weight())
+			}
+			assert.Equal(t, tt.String, tt.Constraint.String("a"))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-air/gini/z"
+)
+
+// CorrectionSet is a set of applied constraints whose removal would
+// make an unsatisfiable problem satisfiable. It is minimal: keeping
+// any one of its constraints would leave the problem unsatisfiable.
+type CorrectionSet []AppliedConstraint
+
+// String implements fmt.Stringer and returns a human-readable
+// suggestion to relax every constraint in the receiver.
+func (c CorrectionSet) String() string {
+	s := make([]string, len(c))
+	for i, a := range c {
+		s[i] = fmt.Sprintf("%q", a.String())
+	}
+	return fmt.Sprintf("relax %s", strings.Join(s, " and "))
+}
+
+// Correctable is returned by Solve instead of NotSatisfiable when
+// correction suggestions have been requested with WithSuggestions. It
+// unwraps to the underlying NotSatisfiable error.
+type Correctable struct {
+	NotSatisfiable
+	// Corrections contains alternative ways to make the problem
+	// satisfiable, smallest first.
+	Corrections []CorrectionSet
+}
+
+func (e Correctable) Error() string {
+	if len(e.Corrections) == 0 {
+		return e.NotSatisfiable.Error()
+	}
+	s := make([]string, len(e.Corrections))
+	for i, c := range e.Corrections {
+		s[i] = c.String()
+	}
+	return fmt.Sprintf("%s; to resolve, %s", e.NotSatisfiable.Error(), strings.Join(s, " OR "))
+}
+
+func (e Correctable) Unwrap() error {
+	return e.NotSatisfiable
+}
+
+// CorrectionSets returns up to limit minimal correction sets, or all
+// of them if limit is not positive, in order of increasing size.
+// Constraints marked as non-relaxable with WithNonRelaxable never
+// appear in a correction set. If the problem is already satisfiable,
+// no correction sets are returned. If it remains unsatisfiable even
+// when every relaxable constraint is removed, a NotSatisfiable error
+// composed of non-relaxable constraints is returned instead.
+func (s *solver) CorrectionSets(ctx context.Context, limit int) (result []CorrectionSet, err error) {
+	defer func() {
+		if derr := s.litMap.Error(); derr != nil {
+			result = nil
+			err = derr
+		}
+	}()
+
+	s.litMap.AddConstraints(s.g)
+	return s.correctionSets(ctx, limit)
+}
+
+func (s *solver) correctionSets(ctx context.Context, limit int) ([]CorrectionSet, error) {
+	var hard, relaxable, violations []z.Lit
+	for _, m := range s.litMap.ConstraintLits() {
+		if s.hard(m) {
+			hard = append(hard, m)
+			continue
+		}
+		relaxable = append(relaxable, m)
+		violations = append(violations, m.Not())
+	}
+
+	// Find assignments violating as few relaxable constraints as
+	// possible. Each set of violated constraints found is ruled
+	// out, along with its supersets, before looking for the next,
+	// so every set found is minimal.
+	cs := s.violationCounter(violations)
+	act := s.litMap.c.Lit()
+	var result []CorrectionSet
+	for w := 0; w <= cs.N() && (limit <= 0 || len(result) < limit); {
+		if err := ctx.Err(); err != nil {
+			return result, Incomplete{Err: err}
+		}
+		bound := cs.Leq(w)
+		s.litMap.assumeAbsent(s.g)
+		s.g.Assume(s.guards...)
+		s.g.Assume(hard...)
+		s.g.Assume(bound, act)
+		switch solveContext(ctx, s.g) {
+		case satisfiable:
+			if w == 0 {
+				return nil, nil
+			}
+			var correction CorrectionSet
+			s.g.Add(act.Not())
+			for _, m := range relaxable {
+				if s.g.Value(m.Not()) {
+					correction = append(correction, s.litMap.ConstraintsOf([]z.Lit{m})...)
+					s.g.Add(m)
+				}
+			}
+			s.g.Add(z.LitNull)
+			result = append(result, correction)
+		case unsatisfiable:
+			if w == cs.N() && len(result) == 0 {
+				return nil, NotSatisfiable(s.litMap.Conflicts(s.g))
+			}
+			w++
+		default:
+			return result, Incomplete{Err: ctx.Err()}
+		}
+	}
+	return result, nil
+}
+
+// violationCounter returns a counter over the provided literals,
+// reusing the one returned by the previous call if it was given the
+// same literals, so that repeatedly looking for correction sets of
+// an unchanged problem does not construct a new counter each time.
+func (s *solver) violationCounter(violations []z.Lit) counter {
+	same := s.violations.cs != nil && len(s.violations.ms) == len(violations)
+	for i := 0; same && i < len(violations); i++ {
+		same = s.violations.ms[i] == violations[i]
+	}
+	if !same {
+		s.violations.ms = violations
+		s.violations.cs = s.litMap.CardinalityConstrainer(s.g, violations)
+	}
+	return s.violations.cs
+}
+
+// hard returns true if any of the constraints encoded by the provided
+// literal must not be relaxed, either because it is an assumption
+// made by SolveUnder or because the caller said so. Constraints that
+// share a literal can only be relaxed together.
+func (s *solver) hard(m z.Lit) bool {
+	for _, a := range s.litMap.ConstraintsOf([]z.Lit{m}) {
+		if _, ok := a.Constraint.(assumption); ok {
+			return true
+		}
+		if s.nonRelaxable != nil && s.nonRelaxable(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectionSetString(t *testing.T) {
+	assert.Equal(t, `relax "a is mandatory" and "b conflicts with a"`, CorrectionSet{
+		{Variable: variable("a"), Constraint: Mandatory()},
+		{Variable: variable("b"), Constraint: Conflict("a")},
+	}.String())
+}
+
+func TestCorrectableError(t *testing.T) {
+	conflicts := NotSatisfiable{
+		{Variable: variable("a"), Constraint: Mandatory()},
+		{Variable: variable("a"), Constraint: Prohibited()},
+	}
+	assert.EqualError(t, Correctable{NotSatisfiable: conflicts}, "constraints not satisfiable: a is mandatory, a is prohibited")
+	assert.EqualError(t, Correctable{
+		NotSatisfiable: conflicts,
+		Corrections: []CorrectionSet{
+			{conflicts[0]},
+			{conflicts[1]},
+		},
+	}, `constraints not satisfiable: a is mandatory, a is prohibited; to resolve, relax "a is mandatory" OR relax "a is prohibited"`)
+}
+
+func TestCorrectionSets(t *testing.T) {
+	anchors := func(a AppliedConstraint) bool {
+		return a.Constraint.Anchor()
+	}
+
+	for _, tt := range []struct {
+		Name        string
+		Variables   []Variable
+		Options     []Option
+		Corrections []CorrectionSet
+		Error       error
+	}{
+		{
+			Name: "satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b")),
+				variable("b"),
+			},
+		},
+		{
+			Name: "single constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Conflict("b")),
+				variable("b", Mandatory()),
+			},
+			Corrections: []CorrectionSet{
+				{{Variable: variable("a", Mandatory(), Conflict("b")), Constraint: Mandatory()}},
+				{{Variable: variable("a", Mandatory(), Conflict("b")), Constraint: Conflict("b")}},
+				{{Variable: variable("b", Mandatory()), Constraint: Mandatory()}},
+			},
+		},
+		{
+			Name: "anchors are not relaxable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Conflict("b")),
+				variable("b", Mandatory()),
+			},
+			Options: []Option{WithNonRelaxable(anchors)},
+			Corrections: []CorrectionSet{
+				{{Variable: variable("a", Mandatory(), Conflict("b")), Constraint: Conflict("b")}},
+			},
+		},
+		{
+			Name: "smallest first",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("b", Mandatory(), Conflict("x")),
+				variable("x", Prohibited()),
+			},
+			Options: []Option{WithNonRelaxable(anchors)},
+			Corrections: []CorrectionSet{
+				{{Variable: variable("a", Mandatory(), Dependency("x")), Constraint: Dependency("x")}},
+				{
+					{Variable: variable("b", Mandatory(), Conflict("x")), Constraint: Conflict("x")},
+					{Variable: variable("x", Prohibited()), Constraint: Prohibited()},
+				},
+			},
+		},
+		{
+			Name: "nothing to relax",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Options: []Option{WithNonRelaxable(func(AppliedConstraint) bool { return true })},
+			Error: NotSatisfiable{
+				{Variable: variable("a", Mandatory(), Prohibited()), Constraint: Mandatory()},
+				{Variable: variable("a", Mandatory(), Prohibited()), Constraint: Prohibited()},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := NewSolver(append(tt.Options, WithInput(tt.Variables))...)
+			assert.NoError(err)
+
+			corrections, err := s.CorrectionSets(context.TODO(), 0)
+			if tt.Error != nil {
+				assert.ElementsMatch(tt.Error, err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Len(corrections, len(tt.Corrections))
+			for i := range corrections {
+				// Sets of the same size may be found
+				// in any order.
+				if i > 0 {
+					assert.LessOrEqual(len(corrections[i-1]), len(corrections[i]))
+				}
+				assert.Contains(tt.Corrections, corrections[i])
+			}
+		})
+	}
+}
+
+func TestCorrectionSetsLimit(t *testing.T) {
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", Mandatory(), Conflict("b")),
+		variable("b", Mandatory()),
+	}))
+	assert.NoError(t, err)
+
+	corrections, err := s.CorrectionSets(context.TODO(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, corrections, 2)
+}
+
+func TestCorrectionSetsAreMinimal(t *testing.T) {
+	solve := func(vars []Variable) error {
+		s, err := NewSolver(WithInput(vars))
+		if err != nil {
+			t.Fatalf("failed to initialize solver: %s", err)
+		}
+		_, err = s.Solve(context.TODO())
+		return err
+	}
+	in := func(corrections CorrectionSet, a AppliedConstraint) bool {
+		for _, each := range corrections {
+			if reflect.DeepEqual(each, a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var checked int
+	for i := 0; checked < 100; i++ {
+		vars := randomVariables(r, 2+r.Intn(8))
+		s, err := newSolver(WithInput(vars))
+		if err != nil {
+			t.Fatalf("failed to initialize solver: %s", err)
+		}
+		corrections, err := s.CorrectionSets(context.TODO(), 0)
+		if err != nil {
+			t.Fatalf("failed to compute correction sets: %s", err)
+		}
+		if len(corrections) == 0 {
+			assert.NoError(t, solve(vars))
+			continue
+		}
+		checked++
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			for _, correction := range corrections {
+				// Removing every constraint in the set
+				// makes the problem satisfiable...
+				assert.NoError(solve(restrict(vars, func(a AppliedConstraint) bool {
+					return !in(correction, a)
+				})), "%#v without %s", vars, correction)
+
+				// ...but keeping any one of them does
+				// not.
+				for _, kept := range correction {
+					assert.Error(solve(restrict(vars, func(a AppliedConstraint) bool {
+						return !in(correction, a) || reflect.DeepEqual(a, kept)
+					})), "%#v without %s except %s", vars, correction, kept)
+				}
+			}
+		})
+	}
+}
+
+func TestSolveSuggestions(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithSuggestions(2), WithNonRelaxable(func(a AppliedConstraint) bool {
+		return a.Variable.Identifier() == "a"
+	}), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("b")),
+		variable("b", Prohibited()),
+	}))
+	assert.NoError(err)
+
+	_, err = s.Solve(context.TODO())
+	assert.Error(err)
+	assert.True(strings.HasSuffix(err.Error(), `; to resolve, relax "b is prohibited"`), err.Error())
+
+	var conflicts NotSatisfiable
+	assert.True(errors.As(err, &conflicts))
+	assert.Len(conflicts, 3)
+
+	var correctable Correctable
+	assert.True(errors.As(err, &correctable))
+	assert.Equal([]CorrectionSet{
+		{{Variable: variable("b", Prohibited()), Constraint: Prohibited()}},
+	}, correctable.Corrections)
+}
@@ -0,0 +1,179 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// components partitions the provided Variables into groups such that
+// no constraint applied to a Variable in one group refers to a
+// Variable in another. Within each group, Variables appear in input
+// order, and groups are ordered by their first Variable.
+func components(vars []Variable) [][]Variable {
+	index := make(map[Identifier]int, len(vars))
+	for i, v := range vars {
+		index[v.Identifier()] = i
+	}
+
+	// parent forms a disjoint-set forest over indices into vars.
+	parent := make([]int, len(vars))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, v := range vars {
+		for _, c := range v.Constraints() {
+			for _, id := range references(c) {
+				j, ok := index[id]
+				if !ok {
+					continue
+				}
+				if a, b := find(i), find(j); a != b {
+					parent[b] = a
+				}
+			}
+		}
+	}
+
+	var result [][]Variable
+	group := make(map[int]int)
+	for i, v := range vars {
+		root := find(i)
+		g, ok := group[root]
+		if !ok {
+			g = len(result)
+			group[root] = g
+			result = append(result, nil)
+		}
+		result[g] = append(result[g], v)
+	}
+	return result
+}
+
+// componentResult holds the outcome of solving a single component.
+type componentResult struct {
+	selection []Variable
+	err       error
+	stats     Stats
+}
+
+// solveComponents solves each of the provided groups of Variables,
+// which must not refer to each other, with a separate solver, using
+// at most GOMAXPROCS goroutines, and merges the results. A selection
+// is returned only if every group is satisfiable, and a
+// NotSatisfiable error includes the conflicts of every group that is
+// not. Groups without a root or a Variable of the prior selection
+// are not solved at all, since selecting none of their Variables is
+// their only minimal solution.
+func (s *solver) solveComponents(ctx context.Context, parts [][]Variable) ([]Variable, error) {
+	var pending []int
+	for i, part := range parts {
+		for _, v := range part {
+			_, prior := s.prior[v.Identifier()]
+			if prior || root(v) {
+				pending = append(pending, i)
+				break
+			}
+		}
+	}
+
+	results := make([]componentResult, len(parts))
+	solve := func(r *componentResult, part []Variable) {
+		options := []Option{
+			WithInput(part),
+			func(c *solver) error {
+				c.minimalConflicts = s.minimalConflicts
+				c.placeholders = s.placeholders
+				c.strategy = s.strategy
+				c.encoding = s.encoding
+				c.prior = s.prior
+				return nil
+			},
+		}
+		if s.statsOut != nil {
+			options = append(options, WithStats(&r.stats))
+		}
+		c, err := newSolver(options...)
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.selection, r.err = c.Solve(ctx)
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				solve(&results[i], parts[i])
+			}
+		}()
+	}
+	for _, i := range pending {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	selected := make(map[Identifier]struct{})
+	var conflicts NotSatisfiable
+	var unsat, incomplete bool
+	for _, r := range results {
+		s.stats.add(r.stats)
+		var ns NotSatisfiable
+		var inc Incomplete
+		switch {
+		case r.err == nil:
+		case errors.As(r.err, &ns):
+			unsat = true
+			conflicts = append(conflicts, ns...)
+			continue
+		case errors.As(r.err, &inc):
+			incomplete = true
+			r.selection = inc.Variables
+		default:
+			return nil, r.err
+		}
+		for _, v := range r.selection {
+			selected[v.Identifier()] = struct{}{}
+		}
+	}
+
+	if unsat {
+		if s.suggestions <= 0 {
+			return nil, conflicts
+		}
+		s.litMap.AddConstraints(s.g)
+		corrections, err := s.correctionSets(ctx, s.suggestions)
+		if err != nil && !errors.As(err, &NotSatisfiable{}) {
+			return nil, err
+		}
+		return nil, Correctable{NotSatisfiable: conflicts, Corrections: corrections}
+	}
+
+	var result []Variable
+	for _, v := range s.litMap.inorder {
+		if _, ok := selected[v.Identifier()]; ok {
+			result = append(result, v)
+		}
+	}
+	if incomplete {
+		return nil, Incomplete{Variables: result, Err: ctx.Err()}
+	}
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponents(t *testing.T) {
+	for _, tt := range []struct {
+		Name       string
+		Variables  []Variable
+		Components [][]Identifier
+	}{
+		{
+			Name: "empty",
+		},
+		{
+			Name: "independent",
+			Variables: []Variable{
+				variable("a"),
+				variable("b"),
+			},
+			Components: [][]Identifier{{"a"}, {"b"}},
+		},
+		{
+			Name: "connected through constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x", Conflict("z")),
+				variable("y"),
+				variable("z"),
+				variable("c", AtMost(1, "y", "w")),
+				variable("w"),
+			},
+			Components: [][]Identifier{{"a", "x", "z"}, {"b", "y", "c", "w"}},
+		},
+		{
+			Name: "connected through operands",
+			Variables: []Variable{
+				variable("a", Not(Selected("c"))),
+				variable("b"),
+				variable("c"),
+			},
+			Components: [][]Identifier{{"a", "c"}, {"b"}},
+		},
+		{
+			Name: "missing references are ignored",
+			Variables: []Variable{
+				variable("a", Dependency("missing")),
+				variable("b", Dependency("missing")),
+			},
+			Components: [][]Identifier{{"a"}, {"b"}},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var actual [][]Identifier
+			for _, part := range components(tt.Variables) {
+				actual = append(actual, identifiers(part))
+			}
+			assert.Equal(t, tt.Components, actual)
+		})
+	}
+}
+
+func TestSolveDecompositionMatchesMonolithic(t *testing.T) {
+	r := rand.New(rand.NewSource(18))
+	for i := 0; i < 200; i++ {
+		var vars []Variable
+		for j := 0; j < 1+r.Intn(4); j++ {
+			vars = append(vars, randomPrefixedVariables(r, fmt.Sprintf("c%d.", j), 1+r.Intn(8))...)
+		}
+		r.Shuffle(len(vars), func(i, j int) {
+			vars[i], vars[j] = vars[j], vars[i]
+		})
+
+		monolithic, err := NewSolver(WithInput(vars))
+		if !assert.NoError(t, err) {
+			return
+		}
+		expected, expectedErr := monolithic.Solve(context.Background())
+
+		decomposed, err := NewSolver(WithInput(vars), WithDecomposition(), WithMinimalConflicts())
+		if !assert.NoError(t, err) {
+			return
+		}
+		actual, err := decomposed.Solve(context.Background())
+
+		if expectedErr != nil {
+			var conflicts NotSatisfiable
+			if assert.True(t, errors.As(err, &conflicts), "expected %v to be unsatisfiable, got %v", identifiers(vars), err) {
+				assert.True(t, errors.As(expectedErr, &NotSatisfiable{}))
+				// Every reported conflict belongs to
+				// the input.
+				assert.Subset(t, appliedConstraints(vars), []AppliedConstraint(conflicts))
+			}
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, identifiers(expected), identifiers(actual))
+	}
+}
+
+func TestSolveDecompositionSuggestions(t *testing.T) {
+	assert := assert.New(t)
+
+	vars := []Variable{
+		variable("a", Mandatory(), Prohibited()),
+		variable("b", Mandatory()),
+		variable("c", Mandatory(), Conflict("d")),
+		variable("d", Mandatory()),
+	}
+	s, err := NewSolver(WithInput(vars), WithDecomposition(), WithMinimalConflicts(), WithSuggestions(4))
+	assert.NoError(err)
+	_, err = s.Solve(context.Background())
+
+	var correctable Correctable
+	if assert.True(errors.As(err, &correctable)) {
+		assert.ElementsMatch(NotSatisfiable{
+			{Variable: vars[0], Constraint: Mandatory()},
+			{Variable: vars[0], Constraint: Prohibited()},
+			{Variable: vars[2], Constraint: Mandatory()},
+			{Variable: vars[2], Constraint: Conflict("d")},
+			{Variable: vars[3], Constraint: Mandatory()},
+		}, correctable.NotSatisfiable)
+		assert.NotEmpty(correctable.Corrections)
+	}
+}
+
+func TestSolveDecompositionStats(t *testing.T) {
+	var stats Stats
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x")),
+		variable("b", Mandatory()),
+		variable("x"),
+	}), WithDecomposition(), WithStats(&stats))
+	assert.NoError(t, err)
+	installed, err := s.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Identifier{"a", "b", "x"}, identifiers(installed))
+	assert.Equal(t, 3, stats.Variables)
+	assert.Equal(t, 2, stats.Anchors)
+}
+
+// appliedConstraints returns every constraint applied to the provided
+// Variables.
+func appliedConstraints(vars []Variable) []AppliedConstraint {
+	var result []AppliedConstraint
+	for _, v := range vars {
+		for _, c := range v.Constraints() {
+			result = append(result, AppliedConstraint{Variable: v, Constraint: c})
+		}
+	}
+	return result
+}
+
+func TestSolveAllDecomposition(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("b", Mandatory(), Dependency("p", "q")),
+		variable("x"),
+		variable("y"),
+		variable("p"),
+		variable("q"),
+	}
+	distinct := func(solutions [][]Variable) map[string]struct{} {
+		result := make(map[string]struct{})
+		for _, solution := range solutions {
+			result[fmt.Sprint(identifiers(solution))] = struct{}{}
+		}
+		return result
+	}
+
+	s, err := NewSolver(WithInput(input), WithDecomposition())
+	assert.NoError(err)
+	all, err := s.SolveAll(context.Background(), 20)
+	assert.NoError(err)
+	assert.Len(all, 9)
+	assert.Len(distinct(all), 9)
+
+	s, err = NewSolver(WithInput(input), WithDecomposition())
+	assert.NoError(err)
+	optimal, err := s.SolveAllOptimal(context.Background(), 20)
+	assert.NoError(err)
+	assert.Len(optimal, 4)
+	assert.Len(distinct(optimal), 4)
+}
+
+func TestSessionDecomposition(t *testing.T) {
+	s, err := NewSession(WithDecomposition())
+	assert.NoError(t, err)
+	assert.False(t, s.decompose)
+}
+
+func TestSolveDecompositionSkipsUnrooted(t *testing.T) {
+	assert := assert.New(t)
+
+	var stats Stats
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b", Dependency("c")),
+		variable("c"),
+	}), WithDecomposition(), WithStats(&stats))
+	assert.NoError(err)
+	installed, err := s.Solve(context.Background())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a"}, identifiers(installed))
+
+	var alone Stats
+	s, err = NewSolver(WithInput([]Variable{
+		variable("a", Mandatory()),
+	}), WithStats(&alone))
+	assert.NoError(err)
+	_, err = s.Solve(context.Background())
+	assert.NoError(err)
+	assert.Equal(alone.Solves, stats.Solves)
+}
@@ -0,0 +1,191 @@
+package sat
+
+import (
+	"encoding/json"
+)
+
+// ConstraintKind identifies the kind of a Constraint in a
+// Description.
+type ConstraintKind string
+
+// The kinds of the Constraints provided by this package are named
+// after the functions that return them, except that Constraints
+// returned by Exactly are of KindBetween.
+const (
+	KindMandatory       ConstraintKind = "mandatory"
+	KindProhibited      ConstraintKind = "prohibited"
+	KindDependency      ConstraintKind = "dependency"
+	KindConflict        ConstraintKind = "conflict"
+	KindAtMost          ConstraintKind = "at-most"
+	KindAtLeast         ConstraintKind = "at-least"
+	KindBetween         ConstraintKind = "between"
+	KindWeightedAtMost  ConstraintKind = "weighted-at-most"
+	KindWeightedAtLeast ConstraintKind = "weighted-at-least"
+	KindPrefer          ConstraintKind = "prefer"
+	KindPenalty         ConstraintKind = "penalty"
+	KindSelected        ConstraintKind = "selected"
+	KindNot             ConstraintKind = "not"
+	KindAll             ConstraintKind = "all"
+	KindAny             ConstraintKind = "any"
+	KindImplies         ConstraintKind = "implies"
+	// KindAssumedSelected and KindAssumedNotSelected describe
+	// the assumptions made by SolveUnder.
+	KindAssumedSelected    ConstraintKind = "assumed-selected"
+	KindAssumedNotSelected ConstraintKind = "assumed-not-selected"
+	// KindCustom describes Constraints not provided by this
+	// package that do not implement Describer.
+	KindCustom ConstraintKind = "custom"
+)
+
+// Description is a machine-readable description of a Constraint
+// applied to a particular Variable.
+type Description struct {
+	Kind ConstraintKind `json:"kind"`
+	// Subject is the Identifier of the Variable the Constraint
+	// is applied to.
+	Subject Identifier `json:"subject"`
+	// References contains the Identifiers of other Variables the
+	// Constraint refers to, in the order they were given. For
+	// composed Constraints, these are found in Operands instead.
+	References []Identifier `json:"references,omitempty"`
+	// Weights contains the weight of each of the References of a
+	// weighted constraint, in the same order.
+	Weights []int `json:"weights,omitempty"`
+	// Min and Max are the bounds of cardinality constraints, if
+	// they have them.
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+	// Weight is the weight of a SoftConstraint.
+	Weight int `json:"weight,omitempty"`
+	// Operands describes the operands of a composed Constraint,
+	// which are applied to the same subject.
+	Operands []Description `json:"operands,omitempty"`
+	// Message is the human-readable message returned by the
+	// Constraint's String method.
+	Message string `json:"message"`
+}
+
+// Describer may be implemented by a Constraint that is not provided
+// by this package to describe itself when applied to subject.
+// Constraints that do not implement it are described as KindCustom,
+// with the References returned by their Order method.
+type Describer interface {
+	Describe(subject Identifier) Description
+}
+
+// Describe returns a machine-readable description of the receiver.
+func (a AppliedConstraint) Describe() Description {
+	return describeConstraint(a.Variable.Identifier(), a.Constraint)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the receiver as its
+// Description.
+func (a AppliedConstraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Describe())
+}
+
+// Descriptions returns a machine-readable description of each applied
+// constraint in the receiver, in the same order.
+func (e NotSatisfiable) Descriptions() []Description {
+	ds := make([]Description, len(e))
+	for i, a := range e {
+		ds[i] = a.Describe()
+	}
+	return ds
+}
+
+// MarshalJSON implements json.Marshaler, encoding the receiver as an
+// object holding its conflicts and the suggested correction sets.
+func (e Correctable) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Conflicts   NotSatisfiable  `json:"conflicts"`
+		Corrections []CorrectionSet `json:"corrections"`
+	}{
+		Conflicts:   e.NotSatisfiable,
+		Corrections: e.Corrections,
+	})
+}
+
+func describeConstraint(subject Identifier, c Constraint) Description {
+	if d, ok := c.(Describer); ok {
+		return d.Describe(subject)
+	}
+	d := Description{Subject: subject, Message: c.String(subject)}
+	bound := func(n int) *int {
+		return &n
+	}
+	switch c := c.(type) {
+	case mandatory:
+		d.Kind = KindMandatory
+	case prohibited:
+		d.Kind = KindProhibited
+	case dependency:
+		d.Kind = KindDependency
+		d.References = c
+	case conflict:
+		d.Kind = KindConflict
+		d.References = []Identifier{Identifier(c)}
+	case leq:
+		d.Kind = KindAtMost
+		d.References = c.ids
+		d.Max = bound(c.n)
+	case geq:
+		d.Kind = KindAtLeast
+		d.References = c.ids
+		d.Min = bound(c.n)
+	case between:
+		d.Kind = KindBetween
+		d.References = c.ids
+		d.Min, d.Max = bound(c.lo), bound(c.hi)
+	case weightedSum:
+		d.Kind = KindWeightedAtMost
+		if c.atLeast {
+			d.Kind = KindWeightedAtLeast
+			d.Min = bound(c.n)
+		} else {
+			d.Max = bound(c.n)
+		}
+		for _, each := range c.terms {
+			d.References = append(d.References, each.Identifier)
+			d.Weights = append(d.Weights, each.Weight)
+		}
+	case prefer:
+		d.Kind = KindPrefer
+		d.Weight = int(c)
+	case penalty:
+		d.Kind = KindPenalty
+		d.Weight = int(c)
+	case selected:
+		d.Kind = KindSelected
+		d.References = []Identifier{Identifier(c)}
+	case not:
+		d.Kind = KindNot
+		d.Operands = describeConstraints(subject, c.operand)
+	case allOf:
+		d.Kind = KindAll
+		d.Operands = describeConstraints(subject, c...)
+	case anyOf:
+		d.Kind = KindAny
+		d.Operands = describeConstraints(subject, c...)
+	case implies:
+		d.Kind = KindImplies
+		d.Operands = describeConstraints(subject, c.antecedent, c.consequent)
+	case assumption:
+		d.Kind = KindAssumedNotSelected
+		if c {
+			d.Kind = KindAssumedSelected
+		}
+	default:
+		d.Kind = KindCustom
+		d.References = c.Order()
+	}
+	return d
+}
+
+func describeConstraints(subject Identifier, cs ...Constraint) []Description {
+	ds := make([]Description, len(cs))
+	for i, c := range cs {
+		ds[i] = describeConstraint(subject, c)
+	}
+	return ds
+}
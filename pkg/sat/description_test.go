@@ -0,0 +1,203 @@
+package sat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// described is a custom Constraint that describes itself.
+type described struct {
+	opaque
+}
+
+func (described) Describe(subject Identifier) Description {
+	return Description{Kind: "described", Subject: subject}
+}
+
+func TestDescribe(t *testing.T) {
+	n := func(n int) *int {
+		return &n
+	}
+
+	for _, tt := range []struct {
+		Name        string
+		Constraint  Constraint
+		Description Description
+	}{
+		{
+			Name:        "mandatory",
+			Constraint:  Mandatory(),
+			Description: Description{Kind: KindMandatory, Subject: "a", Message: "a is mandatory"},
+		},
+		{
+			Name:        "prohibited",
+			Constraint:  Prohibited(),
+			Description: Description{Kind: KindProhibited, Subject: "a", Message: "a is prohibited"},
+		},
+		{
+			Name:       "dependency",
+			Constraint: Dependency("x", "y"),
+			Description: Description{
+				Kind:       KindDependency,
+				Subject:    "a",
+				References: []Identifier{"x", "y"},
+				Message:    "a requires at least one of x, y",
+			},
+		},
+		{
+			Name:       "conflict",
+			Constraint: Conflict("x"),
+			Description: Description{
+				Kind:       KindConflict,
+				Subject:    "a",
+				References: []Identifier{"x"},
+				Message:    "a conflicts with x",
+			},
+		},
+		{
+			Name:       "at most",
+			Constraint: AtMost(0, "x"),
+			Description: Description{
+				Kind:       KindAtMost,
+				Subject:    "a",
+				References: []Identifier{"x"},
+				Max:        n(0),
+				Message:    "a permits at most 0 of x",
+			},
+		},
+		{
+			Name:       "at least",
+			Constraint: AtLeast(2, "x", "y"),
+			Description: Description{
+				Kind:       KindAtLeast,
+				Subject:    "a",
+				References: []Identifier{"x", "y"},
+				Min:        n(2),
+				Message:    "a requires at least 2 of x, y",
+			},
+		},
+		{
+			Name:       "exactly",
+			Constraint: Exactly(1, "x", "y"),
+			Description: Description{
+				Kind:       KindBetween,
+				Subject:    "a",
+				References: []Identifier{"x", "y"},
+				Min:        n(1),
+				Max:        n(1),
+				Message:    "a requires exactly 1 of x, y",
+			},
+		},
+		{
+			Name:       "weighted at least",
+			Constraint: WeightedAtLeast(5, Weighted{Identifier: "x", Weight: 2}, Weighted{Identifier: "y", Weight: 3}),
+			Description: Description{
+				Kind:       KindWeightedAtLeast,
+				Subject:    "a",
+				References: []Identifier{"x", "y"},
+				Weights:    []int{2, 3},
+				Min:        n(5),
+				Message:    "a requires a total weight of at least 5 among x (weight 2), y (weight 3)",
+			},
+		},
+		{
+			Name:        "prefer",
+			Constraint:  Prefer(3),
+			Description: Description{Kind: KindPrefer, Subject: "a", Weight: 3, Message: "a is preferred with weight 3"},
+		},
+		{
+			Name:        "penalty",
+			Constraint:  Penalty(2),
+			Description: Description{Kind: KindPenalty, Subject: "a", Weight: 2, Message: "a is penalized with weight 2"},
+		},
+		{
+			Name:       "composed",
+			Constraint: Implies(Selected("x"), Not(Selected("y"))),
+			Description: Description{
+				Kind:    KindImplies,
+				Subject: "a",
+				Operands: []Description{
+					{Kind: KindSelected, Subject: "a", References: []Identifier{"x"}, Message: Selected("x").String("a")},
+					{
+						Kind:    KindNot,
+						Subject: "a",
+						Operands: []Description{
+							{Kind: KindSelected, Subject: "a", References: []Identifier{"y"}, Message: Selected("y").String("a")},
+						},
+						Message: Not(Selected("y")).String("a"),
+					},
+				},
+				Message: Implies(Selected("x"), Not(Selected("y"))).String("a"),
+			},
+		},
+		{
+			Name:        "custom",
+			Constraint:  opaque{},
+			Description: Description{Kind: KindCustom, Subject: "a", Message: "a is opaque"},
+		},
+		{
+			Name:        "describer",
+			Constraint:  described{},
+			Description: Description{Kind: "described", Subject: "a"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			a := AppliedConstraint{Variable: variable("a", tt.Constraint), Constraint: tt.Constraint}
+			assert.Equal(t, tt.Description, a.Describe())
+		})
+	}
+}
+
+func TestNotSatisfiableDescriptions(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", AtMost(0, "b")),
+		variable("b", Mandatory()),
+	}), WithSuggestions(1))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+
+	var conflicts NotSatisfiable
+	if !assert.True(errors.As(err, &conflicts)) {
+		return
+	}
+	var kinds []ConstraintKind
+	for _, d := range conflicts.Descriptions() {
+		kinds = append(kinds, d.Kind)
+	}
+	assert.ElementsMatch([]ConstraintKind{KindMandatory, KindAtMost}, kinds)
+
+	expected, err := json.Marshal(conflicts.Descriptions())
+	assert.NoError(err)
+	actual, err := json.Marshal(conflicts)
+	assert.NoError(err)
+	assert.JSONEq(string(expected), string(actual))
+}
+
+func TestCorrectableJSON(t *testing.T) {
+	a := variable("a", Mandatory(), Prohibited())
+	b, err := json.Marshal(Correctable{
+		NotSatisfiable: NotSatisfiable{
+			{Variable: a, Constraint: Mandatory()},
+			{Variable: a, Constraint: Prohibited()},
+		},
+		Corrections: []CorrectionSet{
+			{{Variable: a, Constraint: Prohibited()}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"conflicts": [
+			{"kind": "mandatory", "subject": "a", "message": "a is mandatory"},
+			{"kind": "prohibited", "subject": "a", "message": "a is prohibited"}
+		],
+		"corrections": [
+			[{"kind": "prohibited", "subject": "a", "message": "a is prohibited"}]
+		]
+	}`, string(b))
+}
@@ -0,0 +1,155 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-air/gini/z"
+)
+
+// AssumptionMode determines how WriteDIMACS represents the literals
+// that the solver assumes rather than adds as clauses, i.e. the
+// literals of anchors and of constraints.
+type AssumptionMode int
+
+const (
+	// AssumptionsAsUnits writes each assumption as a unit clause,
+	// so that the CNF is satisfiable exactly when the problem is.
+	AssumptionsAsUnits AssumptionMode = iota
+	// AssumptionsAsComments writes each assumption on a comment
+	// line of the form "c assume <literal>", leaving the clauses
+	// free of assumptions.
+	AssumptionsAsComments
+)
+
+// clauses collects the clauses passed to Add.
+type clauses struct {
+	all     [][]z.Lit
+	current []z.Lit
+}
+
+func (c *clauses) Add(m z.Lit) {
+	if m == z.LitNull {
+		c.all = append(c.all, c.current)
+		c.current = nil
+		return
+	}
+	c.current = append(c.current, m)
+}
+
+// Assumptions returns the literals that must be assumed for all
+// constraints to hold, in input order and without duplicates.
+func (d *LitMapping) Assumptions() []z.Lit {
+	var ms []z.Lit
+	seen := make(map[z.Lit]struct{})
+	add := func(m z.Lit) {
+		if _, ok := seen[m]; ok {
+			return
+		}
+		seen[m] = struct{}{}
+		ms = append(ms, m)
+	}
+	for _, id := range d.AnchorIdentifiers() {
+		add(d.LitOf(id))
+	}
+	for _, m := range d.ConstraintLits() {
+		add(m)
+	}
+	var absent []z.Lit
+	for _, m := range d.lits {
+		if !d.Present(m) {
+			absent = append(absent, m.Not())
+		}
+	}
+	sort.Slice(absent, func(i, j int) bool {
+		return absent[i] < absent[j]
+	})
+	return append(ms, absent...)
+}
+
+// WriteDIMACS writes the fully encoded problem, including the
+// variables introduced by the Tseitin transformation of constraints,
+// to cnf in DIMACS CNF format. Assumptions are represented according
+// to mode. If symbols is not nil, a table mapping DIMACS literals back
+// to the Variables and constraints they encode is written to it, one
+// tab-separated entry per line, in one of the forms:
+//
+//	variable <literal> <identifier>
+//	constraint <literal> <identifier> <description>
+//	soft <literal> <identifier> <weight> <description>
+func (d *LitMapping) WriteDIMACS(cnf, symbols io.Writer, mode AssumptionMode) error {
+	var cs clauses
+	roots := d.Assumptions()
+	for _, s := range d.soft {
+		roots = append(roots, s.m)
+	}
+	d.c.CnfSince(&cs, nil, roots...)
+
+	assumptions := d.Assumptions()
+	n := len(cs.all)
+	if mode == AssumptionsAsUnits {
+		n += len(assumptions)
+	}
+
+	w := bufio.NewWriter(cnf)
+	fmt.Fprintf(w, "p cnf %d %d\n", d.c.Len()-1, n)
+	for _, m := range assumptions {
+		switch mode {
+		case AssumptionsAsUnits:
+			fmt.Fprintf(w, "%d 0\n", m.Dimacs())
+		case AssumptionsAsComments:
+			fmt.Fprintf(w, "c assume %d\n", m.Dimacs())
+		}
+	}
+	for _, clause := range cs.all {
+		for _, m := range clause {
+			fmt.Fprintf(w, "%d ", m.Dimacs())
+		}
+		fmt.Fprintln(w, "0")
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if symbols == nil {
+		return nil
+	}
+	w = bufio.NewWriter(symbols)
+	for _, variable := range d.inorder {
+		fmt.Fprintf(w, "variable\t%d\t%s\n", d.LitOf(variable.Identifier()).Dimacs(), variable.Identifier())
+	}
+	for _, variable := range d.inorder {
+		seen := make(map[z.Lit]struct{})
+		for _, m := range d.applied[variable.Identifier()] {
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			for _, a := range d.constraints[m] {
+				if a.Variable.Identifier() == variable.Identifier() {
+					fmt.Fprintf(w, "constraint\t%d\t%s\t%s\n", m.Dimacs(), a.Variable.Identifier(), a)
+				}
+			}
+		}
+	}
+	for _, s := range d.soft {
+		fmt.Fprintf(w, "soft\t%d\t%s\t%d\t%s\n", s.m.Dimacs(), s.subject, s.w, s.constraint.String(s.subject))
+	}
+	return w.Flush()
+}
+
+// WriteDIMACS encodes the problem defined by the provided Variables
+// and writes it to cnf, along with a symbol table to symbols, as
+// described by (*LitMapping).WriteDIMACS.
+func WriteDIMACS(input []Variable, cnf, symbols io.Writer, mode AssumptionMode) error {
+	lm, err := newLitMapping(input)
+	if err != nil {
+		return err
+	}
+	if err := lm.WriteDIMACS(cnf, symbols, mode); err != nil {
+		return err
+	}
+	return lm.Error()
+}
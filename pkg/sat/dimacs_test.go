@@ -0,0 +1,146 @@
+package sat
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-air/gini"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDIMACS(t *testing.T) {
+	assert := assert.New(t)
+
+	var cnf, symbols bytes.Buffer
+	err := WriteDIMACS([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x", Conflict("y"), Penalty(2)),
+		variable("y"),
+	}, &cnf, &symbols, AssumptionsAsUnits)
+	assert.NoError(err)
+
+	assert.Equal(`p cnf 7 13
+2 0
+-6 0
+-7 0
+1 0
+-5 2 0
+-5 -3 0
+5 -2 3 0
+-6 -4 0
+-6 5 0
+6 4 -5 0
+-7 3 0
+-7 4 0
+7 -3 -4 0
+`, cnf.String())
+
+	assert.Equal(`variable	2	a
+variable	3	x
+variable	4	y
+constraint	2	a	a is mandatory
+constraint	-6	a	a requires at least one of x, y
+constraint	-7	x	x conflicts with y
+soft	-3	x	2	x is penalized with weight 2
+`, symbols.String())
+}
+
+func TestWriteDIMACSAssumptionsAsComments(t *testing.T) {
+	assert := assert.New(t)
+
+	var cnf bytes.Buffer
+	err := WriteDIMACS([]Variable{
+		variable("a", Mandatory(), Conflict("b")),
+		variable("b"),
+	}, &cnf, nil, AssumptionsAsComments)
+	assert.NoError(err)
+
+	assert.Equal(`p cnf 4 4
+c assume 2
+c assume -4
+1 0
+-4 2 0
+-4 3 0
+4 -2 -3 0
+`, cnf.String())
+}
+
+func TestWriteDIMACSSharedConstraintLiteral(t *testing.T) {
+	var symbols bytes.Buffer
+	err := WriteDIMACS([]Variable{
+		variable("a", Conflict("b")),
+		variable("b", Conflict("a")),
+	}, &bytes.Buffer{}, &symbols, AssumptionsAsUnits)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `variable	2	a
+variable	3	b
+constraint	-4	a	a conflicts with b
+constraint	-4	b	b conflicts with a
+`, symbols.String())
+}
+
+func TestWriteDIMACSMissingReference(t *testing.T) {
+	err := WriteDIMACS([]Variable{
+		variable("a", Dependency("b")),
+	}, &bytes.Buffer{}, nil, AssumptionsAsUnits)
+	assert.Error(t, err)
+}
+
+func TestWriteDIMACSRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Result    int
+	}{
+		{
+			Name: "satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), AtMost(1, "x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Result: satisfiable,
+		},
+		{
+			Name: "unsatisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), AtMost(1, "x", "y")),
+				variable("x", Mandatory()),
+				variable("y", Mandatory()),
+			},
+			Result: unsatisfiable,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var units bytes.Buffer
+			assert.NoError(WriteDIMACS(tt.Variables, &units, nil, AssumptionsAsUnits))
+			g, err := gini.NewDimacs(&units)
+			assert.NoError(err)
+			assert.Equal(tt.Result, g.Solve())
+
+			var comments bytes.Buffer
+			assert.NoError(WriteDIMACS(tt.Variables, &comments, nil, AssumptionsAsComments))
+			var assumptions []z.Lit
+			scanner := bufio.NewScanner(bytes.NewReader(comments.Bytes()))
+			for scanner.Scan() {
+				if s := strings.TrimPrefix(scanner.Text(), "c assume "); s != scanner.Text() {
+					i, err := strconv.Atoi(s)
+					assert.NoError(err)
+					assumptions = append(assumptions, z.Dimacs2Lit(i))
+				}
+			}
+			g, err = gini.NewDimacs(&comments)
+			assert.NoError(err)
+			g.Assume(assumptions...)
+			assert.Equal(tt.Result, g.Solve())
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package sat
+
+import (
+	"github.com/go-air/gini/z"
+)
+
+// dpll is a Backend implementing the textbook DPLL procedure. It is
+// far slower than gini, but simple enough to be obviously correct,
+// which makes it useful as a reference for testing.
+type dpll struct {
+	clauses [][]z.Lit
+	clause  []z.Lit
+	// scopes holds the assumptions of each test scope, innermost
+	// last
+	scopes [][]z.Lit
+	// pending holds assumptions not yet covered by a test scope;
+	// they are forgotten after the next call to Solve
+	pending []z.Lit
+	// failed holds the assumptions in effect when the last test or
+	// solve was found to be unsatisfiable
+	failed []z.Lit
+	// model holds the assignment found by the last satisfiable
+	// test or solve, indexed by variable
+	model []bool
+}
+
+// NewDPLLBackend returns a Backend that uses a simple DPLL solver
+// instead of gini. It is intended to serve as a reference for testing
+// other Backends, not for production use.
+func NewDPLLBackend() Backend {
+	return &dpll{}
+}
+
+func (d *dpll) Add(m z.Lit) {
+	if m == z.LitNull {
+		d.clauses = append(d.clauses, d.clause)
+		d.clause = nil
+		return
+	}
+	d.clause = append(d.clause, m)
+}
+
+func (d *dpll) Assume(ms ...z.Lit) {
+	d.pending = append(d.pending, ms...)
+}
+
+// Why returns a minimal subset of the failed assumptions, found by
+// removing each in turn and keeping the removal if the remaining
+// assumptions are still unsatisfiable.
+func (d *dpll) Why(dst []z.Lit) []z.Lit {
+	core := append([]z.Lit(nil), d.failed...)
+	for i := 0; i < len(core); {
+		rest := append(append([]z.Lit(nil), core[:i]...), core[i+1:]...)
+		if _, ok := d.solve(rest); ok {
+			i++
+		} else {
+			core = rest
+		}
+	}
+	return append(dst[:0], core...)
+}
+
+func (d *dpll) Value(m z.Lit) bool {
+	v := int(m.Var())
+	if v >= len(d.model) {
+		return !m.IsPos()
+	}
+	return d.model[v] == m.IsPos()
+}
+
+func (d *dpll) Test(dst []z.Lit) (int, []z.Lit) {
+	d.scopes = append(d.scopes, d.pending)
+	d.pending = nil
+	assumptions := d.assumptions()
+	assignment, ok := d.propagate(d.assign(assumptions))
+	if !ok {
+		d.failed = assumptions
+		return unsatisfiable, dst
+	}
+	for v := 1; v < len(assignment); v++ {
+		if assignment[v] == 0 {
+			return unknown, dst
+		}
+	}
+	d.model = d.toModel(assignment)
+	return satisfiable, dst
+}
+
+func (d *dpll) Untest() int {
+	d.scopes = d.scopes[:len(d.scopes)-1]
+	d.pending = nil
+	assumptions := d.assumptions()
+	if _, ok := d.propagate(d.assign(assumptions)); !ok {
+		d.failed = assumptions
+		return unsatisfiable
+	}
+	return unknown
+}
+
+func (d *dpll) Solve() int {
+	assumptions := append(d.assumptions(), d.pending...)
+	d.pending = nil
+	model, ok := d.solve(assumptions)
+	if !ok {
+		d.failed = assumptions
+		return unsatisfiable
+	}
+	d.model = model
+	return satisfiable
+}
+
+// assumptions returns the assumptions of all test scopes.
+func (d *dpll) assumptions() []z.Lit {
+	var ms []z.Lit
+	for _, scope := range d.scopes {
+		ms = append(ms, scope...)
+	}
+	return ms
+}
+
+// maxVar returns the largest variable mentioned by any clause or by
+// the provided literals.
+func (d *dpll) maxVar(ms []z.Lit) z.Var {
+	var max z.Var
+	update := func(ms []z.Lit) {
+		for _, m := range ms {
+			if m.Var() > max {
+				max = m.Var()
+			}
+		}
+	}
+	for _, c := range d.clauses {
+		update(c)
+	}
+	update(ms)
+	return max
+}
+
+// assign returns an assignment, indexed by variable, with 1 for true,
+// -1 for false and 0 for unassigned, in which the provided literals
+// are true. It returns nil if they contradict each other.
+func (d *dpll) assign(ms []z.Lit) []int8 {
+	assignment := make([]int8, d.maxVar(ms)+1)
+	for _, m := range ms {
+		if !set(assignment, m) {
+			return nil
+		}
+	}
+	return assignment
+}
+
+// set makes m true in assignment, returning false if it is already
+// false.
+func set(assignment []int8, m z.Lit) bool {
+	value := int8(1)
+	if !m.IsPos() {
+		value = -1
+	}
+	switch assignment[m.Var()] {
+	case 0:
+		assignment[m.Var()] = value
+		return true
+	case value:
+		return true
+	}
+	return false
+}
+
+// propagate repeatedly assigns the only unassigned literal of each
+// clause with no true literals until no such clauses remain. It
+// returns false if a clause with all literals false is found.
+func (d *dpll) propagate(assignment []int8) ([]int8, bool) {
+	if assignment == nil {
+		return nil, false
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, c := range d.clauses {
+			var unassigned []z.Lit
+			satisfied := false
+			for _, m := range c {
+				switch value := assignment[m.Var()]; {
+				case value == 0:
+					unassigned = append(unassigned, m)
+				case (value > 0) == m.IsPos():
+					satisfied = true
+				}
+			}
+			if satisfied {
+				continue
+			}
+			switch len(unassigned) {
+			case 0:
+				return nil, false
+			case 1:
+				set(assignment, unassigned[0])
+				changed = true
+			}
+		}
+	}
+	return assignment, true
+}
+
+// solve returns a model in which all clauses and the provided
+// assumptions are true, if there is one.
+func (d *dpll) solve(assumptions []z.Lit) ([]bool, bool) {
+	assignment, ok := d.search(d.assign(assumptions))
+	if !ok {
+		return nil, false
+	}
+	return d.toModel(assignment), true
+}
+
+func (d *dpll) search(assignment []int8) ([]int8, bool) {
+	assignment, ok := d.propagate(assignment)
+	if !ok {
+		return nil, false
+	}
+	for v := 1; v < len(assignment); v++ {
+		if assignment[v] != 0 {
+			continue
+		}
+		for _, m := range []z.Lit{z.Var(v).Pos(), z.Var(v).Neg()} {
+			branch := append([]int8(nil), assignment...)
+			set(branch, m)
+			if result, ok := d.search(branch); ok {
+				return result, true
+			}
+		}
+		return nil, false
+	}
+	return assignment, true
+}
+
+func (d *dpll) toModel(assignment []int8) []bool {
+	model := make([]bool, len(assignment))
+	for v, value := range assignment {
+		model[v] = value > 0
+	}
+	return model
+}
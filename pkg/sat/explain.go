@@ -0,0 +1,202 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-air/gini/z"
+)
+
+// Explanation describes why a Variable is or is not part of a
+// solution.
+type Explanation struct {
+	// Identifier identifies the explained Variable.
+	Identifier Identifier `json:"identifier"`
+	// Selected is true if the Variable is part of the solution.
+	Selected bool `json:"selected"`
+	// Chain is only populated for selected Variables. It is the
+	// shortest sequence of applied constraints leading from an
+	// anchor to the explained Variable: the first element is the
+	// anchoring constraint, and each subsequent element is a
+	// Dependency of the Variable reached by the preceding element.
+	Chain []AppliedConstraint `json:"chain,omitempty"`
+	// Reasons contains a minimal set of applied constraints that
+	// leave no choice: a selected Variable could not be left out,
+	// and an unselected Variable could not be selected, without
+	// violating at least one of them. It is empty if the outcome
+	// was a matter of preference rather than necessity.
+	Reasons []AppliedConstraint `json:"reasons,omitempty"`
+	// Alternatives is only populated for unselected Variables. It
+	// contains the dependencies of selected Variables that list
+	// the explained Variable as a candidate but that were
+	// satisfied by other candidates.
+	Alternatives []AppliedConstraint `json:"alternatives,omitempty"`
+	// Pruned is true if the Variable was left out of the input
+	// by WithPruning, since it cannot be reached from any
+	// anchor, and so could not be selected.
+	Pruned bool `json:"pruned,omitempty"`
+}
+
+// String implements fmt.Stringer and returns a human-readable message
+// representing the receiver.
+func (e Explanation) String() string {
+	join := func(as []AppliedConstraint, sep string) string {
+		s := make([]string, len(as))
+		for i, a := range as {
+			s[i] = a.String()
+		}
+		return strings.Join(s, sep)
+	}
+	if e.Selected {
+		switch {
+		case len(e.Chain) > 0:
+			return fmt.Sprintf("%s is selected because %s", e.Identifier, join(e.Chain, ", and "))
+		case len(e.Reasons) > 0:
+			return fmt.Sprintf("%s is selected because %s", e.Identifier, join(e.Reasons, ", and "))
+		}
+		return fmt.Sprintf("%s is selected, but is not required", e.Identifier)
+	}
+	switch {
+	case e.Pruned:
+		return fmt.Sprintf("%s is not selected because it cannot be reached from any anchor", e.Identifier)
+	case len(e.Reasons) > 0:
+		return fmt.Sprintf("%s is not selected because %s", e.Identifier, join(e.Reasons, ", and "))
+	case len(e.Alternatives) > 0:
+		return fmt.Sprintf("%s is not selected because other candidates were preferred: %s", e.Identifier, join(e.Alternatives, ", and "))
+	}
+	return fmt.Sprintf("%s is not selected because it is not required", e.Identifier)
+}
+
+// Explain returns an Explanation of why the Variable identified by id
+// is or is not among the selected Variables, which should be a
+// solution previously returned by the receiver.
+func (s *solver) Explain(ctx context.Context, selection []Variable, id Identifier) (result Explanation, err error) {
+	defer func() {
+		if derr := s.litMap.Error(); derr != nil {
+			result = Explanation{}
+			err = derr
+		}
+	}()
+
+	if _, ok := s.pruned[id]; ok {
+		return Explanation{Identifier: id, Pruned: true}, nil
+	}
+	m, ok := s.litMap.lits[id]
+	if !ok || !s.litMap.Present(m) {
+		return Explanation{}, fmt.Errorf("no variable with identifier %q", id)
+	}
+	s.litMap.AddConstraints(s.g)
+
+	selected := make(map[Identifier]struct{}, len(selection))
+	for _, v := range selection {
+		selected[v.Identifier()] = struct{}{}
+	}
+	_, result.Selected = selected[id]
+	result.Identifier = id
+
+	if result.Selected {
+		result.Chain = s.chain(selected, id)
+		m = m.Not()
+	} else {
+		result.Alternatives = s.alternatives(selected, id)
+	}
+
+	// The outcome is necessary if the opposite contradicts the
+	// constraints.
+	if err := ctx.Err(); err != nil {
+		return Explanation{}, Incomplete{Err: err}
+	}
+	background := append([]z.Lit{m}, s.guards...)
+	s.litMap.AssumeConstraints(s.g)
+	s.g.Assume(background...)
+	switch solveContext(ctx, s.g) {
+	case unsatisfiable:
+		reasons, err := s.minimizeConflicts(ctx, s.litMap.ConflictLits(s.g), background...)
+		if err != nil {
+			return Explanation{}, err
+		}
+		result.Reasons = s.litMap.ConstraintsOf(reasons)
+	case unknown:
+		return Explanation{}, Incomplete{Err: ctx.Err()}
+	}
+	return result, nil
+}
+
+// chain returns the shortest sequence of applied constraints leading
+// from an anchor, through the dependencies of selected Variables, to
+// the Variable identified by id, or nil if there is none.
+func (s *solver) chain(selected map[Identifier]struct{}, id Identifier) []AppliedConstraint {
+	type step struct {
+		applied AppliedConstraint
+		prev    *step
+	}
+	var queue []Identifier
+	reached := make(map[Identifier]*step)
+	for _, v := range s.litMap.inorder {
+		if _, ok := selected[v.Identifier()]; !ok {
+			continue
+		}
+		for _, c := range v.Constraints() {
+			if c.Anchor() {
+				reached[v.Identifier()] = &step{applied: AppliedConstraint{Variable: v, Constraint: c}}
+				queue = append(queue, v.Identifier())
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == id {
+			var result []AppliedConstraint
+			for p := reached[current]; p != nil; p = p.prev {
+				result = append([]AppliedConstraint{p.applied}, result...)
+			}
+			return result
+		}
+		v := s.litMap.VariableOf(s.litMap.LitOf(current))
+		for _, c := range v.Constraints() {
+			for _, next := range c.Order() {
+				if _, ok := selected[next]; !ok {
+					continue
+				}
+				if _, ok := reached[next]; ok {
+					continue
+				}
+				reached[next] = &step{
+					applied: AppliedConstraint{Variable: v, Constraint: c},
+					prev:    reached[current],
+				}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// alternatives returns the constraints of selected Variables that
+// list the Variable identified by id as a candidate, but that are
+// satisfied by other selected candidates.
+func (s *solver) alternatives(selected map[Identifier]struct{}, id Identifier) []AppliedConstraint {
+	var result []AppliedConstraint
+	for _, v := range s.litMap.inorder {
+		if _, ok := selected[v.Identifier()]; !ok {
+			continue
+		}
+		for _, c := range v.Constraints() {
+			var candidate, satisfied bool
+			for _, each := range c.Order() {
+				if each == id {
+					candidate = true
+				} else if _, ok := selected[each]; ok {
+					satisfied = true
+				}
+			}
+			if candidate && satisfied {
+				result = append(result, AppliedConstraint{Variable: v, Constraint: c})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package sat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("b")),
+		variable("b", Dependency("x", "y")),
+		variable("c", Mandatory(), AtMost(1, "p", "q")),
+		variable("x"),
+		variable("y"),
+		variable("z", Prohibited()),
+		variable("p", Mandatory(), Conflict("r")),
+		variable("q"),
+		variable("r", Conflict("p")),
+	}
+
+	for _, tt := range []struct {
+		Name        string
+		Identifier  Identifier
+		Explanation Explanation
+		String      string
+	}{
+		{
+			Name:       "anchor",
+			Identifier: "a",
+			Explanation: Explanation{
+				Identifier: "a",
+				Selected:   true,
+				Chain: []AppliedConstraint{
+					{Variable: input[0], Constraint: Mandatory()},
+				},
+				Reasons: []AppliedConstraint{
+					{Variable: input[0], Constraint: Mandatory()},
+				},
+			},
+			String: "a is selected because a is mandatory",
+		},
+		{
+			Name:       "dependency chain",
+			Identifier: "x",
+			Explanation: Explanation{
+				Identifier: "x",
+				Selected:   true,
+				Chain: []AppliedConstraint{
+					{Variable: input[0], Constraint: Mandatory()},
+					{Variable: input[0], Constraint: Dependency("b")},
+					{Variable: input[1], Constraint: Dependency("x", "y")},
+				},
+			},
+			String: "x is selected because a is mandatory, and a requires at least one of b, and b requires at least one of x, y",
+		},
+		{
+			Name:       "preferred alternative",
+			Identifier: "y",
+			Explanation: Explanation{
+				Identifier: "y",
+				Alternatives: []AppliedConstraint{
+					{Variable: input[1], Constraint: Dependency("x", "y")},
+				},
+			},
+			String: "y is not selected because other candidates were preferred: b requires at least one of x, y",
+		},
+		{
+			Name:       "prohibited",
+			Identifier: "z",
+			Explanation: Explanation{
+				Identifier: "z",
+				Reasons: []AppliedConstraint{
+					{Variable: input[5], Constraint: Prohibited()},
+				},
+			},
+			String: "z is not selected because z is prohibited",
+		},
+		{
+			Name:       "ruled out by cardinality",
+			Identifier: "q",
+			Explanation: Explanation{
+				Identifier: "q",
+				Reasons: []AppliedConstraint{
+					{Variable: input[2], Constraint: AtMost(1, "p", "q")},
+					{Variable: input[6], Constraint: Mandatory()},
+				},
+			},
+		},
+		{
+			Name:       "ruled out by conflicts declared from both sides",
+			Identifier: "r",
+			Explanation: Explanation{
+				Identifier: "r",
+				Reasons: []AppliedConstraint{
+					{Variable: input[6], Constraint: Mandatory()},
+					{Variable: input[6], Constraint: Conflict("r")},
+					{Variable: input[8], Constraint: Conflict("p")},
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := NewSolver(WithInput(input))
+			assert.NoError(err)
+			selection, err := s.Solve(context.TODO())
+			assert.NoError(err)
+			assert.Equal([]Identifier{"a", "b", "c", "x", "p"}, identifiers(selection))
+
+			explanation, err := s.Explain(context.TODO(), selection, tt.Identifier)
+			assert.NoError(err)
+			assert.Equal(tt.Explanation.Identifier, explanation.Identifier)
+			assert.Equal(tt.Explanation.Selected, explanation.Selected)
+			assert.Equal(tt.Explanation.Chain, explanation.Chain)
+			assert.ElementsMatch(tt.Explanation.Reasons, explanation.Reasons)
+			assert.Equal(tt.Explanation.Alternatives, explanation.Alternatives)
+			// Reasons are reported in no particular order.
+			if tt.String != "" {
+				assert.Equal(tt.String, explanation.String())
+			}
+		})
+	}
+}
+
+func TestExplainNotRequired(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b"),
+	}))
+	assert.NoError(err)
+	selection, err := s.Solve(context.TODO())
+	assert.NoError(err)
+
+	explanation, err := s.Explain(context.TODO(), selection, "b")
+	assert.NoError(err)
+	assert.Equal("b is not selected because it is not required", explanation.String())
+
+	_, err = s.Explain(context.TODO(), selection, "c")
+	assert.EqualError(err, `no variable with identifier "c"`)
+}
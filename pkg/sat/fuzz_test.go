@@ -0,0 +1,251 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// instanceReader decodes a problem instance from fuzzer input. Reads
+// past the end of the input return zero, so every input decodes to
+// some instance.
+type instanceReader []byte
+
+func (r *instanceReader) next(n int) int {
+	if len(*r) == 0 {
+		return 0
+	}
+	b := (*r)[0]
+	*r = (*r)[1:]
+	return int(b) % n
+}
+
+// decodeInstance returns between one and eight Variables, with
+// constraints chosen by the input, that only refer to each other.
+func decodeInstance(data []byte) []Variable {
+	r := instanceReader(data)
+	n := 1 + r.next(8)
+	ids := make([]Identifier, n)
+	for i := range ids {
+		ids[i] = Identifier(fmt.Sprintf("v%d", i))
+	}
+	some := func() []Identifier {
+		var result []Identifier
+		mask := r.next(1 << n)
+		for i, id := range ids {
+			if mask&(1<<i) != 0 {
+				result = append(result, id)
+			}
+		}
+		return result
+	}
+	vars := make([]Variable, n)
+	for i, id := range ids {
+		var cs []Constraint
+		flags := r.next(256)
+		if flags&1 != 0 {
+			cs = append(cs, Mandatory())
+		}
+		if flags&2 != 0 {
+			cs = append(cs, Prohibited())
+		}
+		if flags&4 != 0 {
+			cs = append(cs, Dependency(some()...))
+		}
+		if flags&8 != 0 {
+			cs = append(cs, Conflict(ids[r.next(n)]))
+		}
+		if flags&16 != 0 {
+			cs = append(cs, AtMost(r.next(3), some()...))
+		}
+		if flags&32 != 0 {
+			cs = append(cs, AtLeast(r.next(3), some()...))
+		}
+		if flags&64 != 0 {
+			cs = append(cs, Prefer(1+r.next(3)))
+		}
+		if flags&128 != 0 {
+			cs = append(cs, Penalty(1+r.next(3)))
+		}
+		vars[i] = variable(id, cs...)
+	}
+	return vars
+}
+
+// enumerator decides problems by exhaustively checking every
+// possible selection against the semantics of their constraints, as
+// implemented by Verify, independently of how they are encoded.
+type enumerator struct {
+	vars  []Variable
+	index map[Identifier]int
+}
+
+func newEnumerator(vars []Variable) *enumerator {
+	index := make(map[Identifier]int, len(vars))
+	for i, v := range vars {
+		index[v.Identifier()] = i
+	}
+	return &enumerator{vars: vars, index: index}
+}
+
+// evaluate returns whether the selection identified by the set bits
+// of mask satisfies all constraints, and if so, its total cost.
+func (e *enumerator) evaluate(mask int) (bool, int) {
+	var selection []Variable
+	for i, v := range e.vars {
+		if mask&(1<<i) != 0 {
+			selection = append(selection, v)
+		}
+	}
+	if len(Verify(e.vars, selection)) > 0 {
+		return false, 0
+	}
+	selected := func(id Identifier) bool {
+		i, ok := e.index[id]
+		return ok && mask&(1<<i) != 0
+	}
+	var cost int
+	for _, v := range e.vars {
+		for _, c := range v.Constraints() {
+			sc, ok := c.(SoftConstraint)
+			if !ok || sc.Weight() <= 0 {
+				continue
+			}
+			if holds, _ := evaluate(c, v.Identifier(), selected); !holds {
+				cost += sc.Weight()
+			}
+		}
+	}
+	return true, cost
+}
+
+// mask returns the mask corresponding to a selection.
+func (e *enumerator) mask(selection []Variable) int {
+	var mask int
+	for _, v := range selection {
+		mask |= 1 << e.index[v.Identifier()]
+	}
+	return mask
+}
+
+// optimal returns the masks of all solutions with the lowest cost
+// and, among those, the fewest selected Variables, as well as that
+// cost. It returns no masks if there is no solution.
+func (e *enumerator) optimal() (map[int]struct{}, int) {
+	var masks map[int]struct{}
+	var bestCost, bestSize int
+	for mask := 0; mask < 1<<len(e.vars); mask++ {
+		ok, cost := e.evaluate(mask)
+		if !ok {
+			continue
+		}
+		size := popcount(mask)
+		if masks == nil || cost < bestCost || (cost == bestCost && size < bestSize) {
+			masks = make(map[int]struct{})
+			bestCost, bestSize = cost, size
+		}
+		if cost == bestCost && size == bestSize {
+			masks[mask] = struct{}{}
+		}
+	}
+	return masks, bestCost
+}
+
+func popcount(mask int) int {
+	var n int
+	for ; mask != 0; mask &= mask - 1 {
+		n++
+	}
+	return n
+}
+
+// checkInstance compares the results of solving vars with those of
+// exhaustive enumeration.
+func checkInstance(t *testing.T, vars []Variable, options ...Option) {
+	e := newEnumerator(vars)
+	optimal, cost := e.optimal()
+
+	s, err := NewSolver(append(options, WithInput(vars))...)
+	if err != nil {
+		t.Fatalf("failed to initialize solver: %s", err)
+	}
+	selection, err := s.Solve(context.TODO())
+
+	var conflicts NotSatisfiable
+	switch {
+	case errors.As(err, &conflicts):
+		if len(optimal) > 0 {
+			t.Fatalf("%#v: solver reports %s, but a solution exists", vars, err)
+		}
+		// The reported constraints must be unsatisfiable on
+		// their own.
+		core := newEnumerator(restrict(vars, func(a AppliedConstraint) bool {
+			for _, each := range conflicts {
+				if reflect.DeepEqual(each, a) {
+					return true
+				}
+			}
+			return false
+		}))
+		if masks, _ := core.optimal(); len(masks) > 0 {
+			t.Fatalf("%#v: reported conflicts %s are satisfiable", vars, err)
+		}
+		return
+	case err != nil:
+		t.Fatalf("%#v: unexpected error: %s", vars, err)
+	case len(optimal) == 0:
+		t.Fatalf("%#v: solver returned %v, but no solution exists", vars, identifiers(selection))
+	}
+
+	if violations := Verify(vars, selection); len(violations) > 0 {
+		t.Fatalf("%#v: solution %v fails verification: %v", vars, identifiers(selection), violations)
+	}
+	if _, c := e.evaluate(e.mask(selection)); c != cost {
+		t.Fatalf("%#v: solution %v has cost %d, but the minimum is %d", vars, identifiers(selection), c, cost)
+	}
+
+	// Optimal solutions are exactly those of minimal cost and
+	// cardinality.
+	solutions, err := s.SolveAllOptimal(context.TODO(), 0)
+	if err != nil {
+		t.Fatalf("%#v: unexpected error: %s", vars, err)
+	}
+	if len(solutions) != len(optimal) {
+		t.Fatalf("%#v: solver returned %d optimal solutions, expected %d", vars, len(solutions), len(optimal))
+	}
+	for _, solution := range solutions {
+		if _, ok := optimal[e.mask(solution)]; !ok {
+			t.Fatalf("%#v: solution %v is not optimal", vars, identifiers(solution))
+		}
+	}
+}
+
+// FuzzSolve checks the results of Solve and SolveAllOptimal, using
+// both gini and the DPLL reference backend, against exhaustive
+// enumeration of small random instances. Run it with
+//
+//	go test -fuzz FuzzSolve ./pkg/sat
+//
+// Inputs that cause failures are saved under testdata/fuzz/FuzzSolve
+// and replayed by subsequent runs of go test.
+func FuzzSolve(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 5, 2, 4, 0})
+	f.Add([]byte{3, 13, 6, 2, 1, 9, 4, 2, 16, 7, 3})
+	f.Add([]byte{7, 255, 255, 3, 1, 2, 3, 1, 1, 37, 12, 2, 0, 80, 1, 2, 133, 2, 0})
+	for i := 0; i < 32; i++ {
+		seed := make([]byte, 32)
+		for j := range seed {
+			seed[j] = byte(i*31 + j*17)
+		}
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		vars := decodeInstance(data)
+		checkInstance(t, vars)
+		checkInstance(t, vars, WithBackend(NewDPLLBackend()))
+	})
+}
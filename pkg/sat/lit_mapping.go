@@ -15,6 +15,13 @@ func (e DuplicateIdentifier) Error() string {
 	return fmt.Sprintf("duplicate identifier %q in input", Identifier(e))
 }
 
+type weightedLit struct {
+	m          z.Lit
+	w          int
+	subject    Identifier
+	constraint Constraint
+}
+
 type inconsistentLitMapping []error
 
 func (inconsistentLitMapping) Error() string {
@@ -28,9 +35,18 @@ type LitMapping struct {
 	inorder     []Variable
 	variables   map[z.Lit]Variable
 	lits        map[Identifier]z.Lit
-	constraints map[z.Lit]AppliedConstraint
+	constraints map[z.Lit][]AppliedConstraint // every constraint encoded by each literal, in the order applied
+	applied     map[Identifier][]z.Lit        // literals of the constraints applied to each Variable
+	soft        []weightedLit
 	c           *logic.C
+	marks       []int8 // nodes of c that have already been translated to CNF
 	errs        inconsistentLitMapping
+	// placeholders, if set, allows constraints to reference
+	// Identifiers that are not part of the input. Such references
+	// are mapped to literals that are assumed to be false.
+	placeholders bool
+	// encoding determines how cardinality constraints are encoded
+	encoding CardinalityEncoding
 }
 
 // newLitMapping returns a new LitMapping with its state initialized based on
@@ -39,26 +55,51 @@ type LitMapping struct {
 // inputs to the underlying solver.
 func newLitMapping(variables []Variable) (*LitMapping, error) {
 	d := LitMapping{
-		inorder:     variables,
 		variables:   make(map[z.Lit]Variable, len(variables)),
 		lits:        make(map[Identifier]z.Lit, len(variables)),
-		constraints: make(map[z.Lit]AppliedConstraint),
+		constraints: make(map[z.Lit][]AppliedConstraint),
+		applied:     make(map[Identifier][]z.Lit, len(variables)),
 		c:           logic.NewCCap(len(variables)),
 	}
+	if err := d.add(variables); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// add extends the translation tables with the provided Variables and
+// applies their constraints. A Variable that has been removed may be
+// added again, in which case it is assigned the same literal as
+// before.
+func (d *LitMapping) add(variables []Variable) error {
+	seen := make(map[Identifier]struct{}, len(variables))
+	for _, variable := range variables {
+		id := variable.Identifier()
+		if _, ok := seen[id]; ok {
+			return DuplicateIdentifier(id)
+		}
+		if m, ok := d.lits[id]; ok {
+			if _, ok := d.variables[m]; ok {
+				return DuplicateIdentifier(id)
+			}
+		}
+		seen[id] = struct{}{}
+	}
 
 	// First pass to assign lits:
 	for _, variable := range variables {
-		im := d.c.Lit()
-		if _, ok := d.lits[variable.Identifier()]; ok {
-			return nil, DuplicateIdentifier(variable.Identifier())
+		im, ok := d.lits[variable.Identifier()]
+		if !ok {
+			im = d.c.Lit()
+			d.lits[variable.Identifier()] = im
 		}
-		d.lits[variable.Identifier()] = im
 		d.variables[im] = variable
+		d.inorder = append(d.inorder, variable)
 	}
 
 	for _, variable := range variables {
 		for _, constraint := range variable.Constraints() {
-			m := constraint.Apply(d.c, &d, variable.Identifier())
+			m := constraint.Apply(d.c, d, variable.Identifier())
 			if m == z.LitNull {
 				// This constraint doesn't have a
 				// useful representation in the SAT
@@ -66,14 +107,112 @@ func newLitMapping(variables []Variable) (*LitMapping, error) {
 				continue
 			}
 
-			d.constraints[m] = AppliedConstraint{
+			if sc, ok := constraint.(SoftConstraint); ok {
+				if w := sc.Weight(); w > 0 {
+					d.soft = append(d.soft, weightedLit{m: m, w: w, subject: variable.Identifier(), constraint: constraint})
+				}
+				continue
+			}
+
+			d.constraints[m] = append(d.constraints[m], AppliedConstraint{
 				Variable:   variable,
 				Constraint: constraint,
+			})
+			d.applied[variable.Identifier()] = append(d.applied[variable.Identifier()], m)
+		}
+	}
+
+	return nil
+}
+
+// assume temporarily applies an assumption that the provided
+// Variable is, or is not, selected, until unassume is called. It
+// returns the literal encoding the assumption.
+func (d *LitMapping) assume(variable Variable, value bool) z.Lit {
+	c := assumption(value)
+	m := c.Apply(d.c, d, variable.Identifier())
+	d.constraints[m] = append(d.constraints[m], AppliedConstraint{
+		Variable:   variable,
+		Constraint: c,
+	})
+	d.applied[variable.Identifier()] = append(d.applied[variable.Identifier()], m)
+	return m
+}
+
+// unassume withdraws the assumptions applied by assume to the
+// Variables with the provided Identifiers.
+func (d *LitMapping) unassume(ids []Identifier) {
+	for _, id := range ids {
+		ms := d.applied[id]
+		m := ms[len(ms)-1]
+		d.applied[id] = ms[:len(ms)-1]
+		var as []AppliedConstraint
+		for _, a := range d.constraints[m] {
+			if _, ok := a.Constraint.(assumption); !ok {
+				as = append(as, a)
 			}
 		}
+		if len(as) == 0 {
+			delete(d.constraints, m)
+			continue
+		}
+		d.constraints[m] = as
 	}
+}
 
-	return &d, nil
+// remove removes the Variables with the provided Identifiers, along
+// with the constraints applied to them, from the translation tables.
+// Their literals remain allocated, but are assumed to be false by
+// AssumeConstraints.
+func (d *LitMapping) remove(ids []Identifier) {
+	removed := make(map[Identifier]struct{}, len(ids))
+	for _, id := range ids {
+		m, ok := d.lits[id]
+		if !ok {
+			continue
+		}
+		if _, ok := d.variables[m]; !ok {
+			continue
+		}
+		removed[id] = struct{}{}
+		delete(d.variables, m)
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	inorder := d.inorder[:0:0]
+	for _, variable := range d.inorder {
+		if _, ok := removed[variable.Identifier()]; !ok {
+			inorder = append(inorder, variable)
+		}
+	}
+	d.inorder = inorder
+
+	for id := range removed {
+		for _, m := range d.applied[id] {
+			as := d.constraints[m][:0:0]
+			for _, a := range d.constraints[m] {
+				if a.Variable.Identifier() != id {
+					as = append(as, a)
+				}
+			}
+			if len(as) == 0 {
+				delete(d.constraints, m)
+				continue
+			}
+			d.constraints[m] = as
+		}
+		delete(d.applied, id)
+	}
+
+	soft := d.soft[:0:0]
+	for _, s := range d.soft {
+		if _, ok := removed[s.subject]; !ok {
+			soft = append(soft, s)
+		}
+	}
+	d.soft = soft
 }
 
 // LitOf returns the positive literal corresponding to the Variable
@@ -83,6 +222,11 @@ func (d *LitMapping) LitOf(id Identifier) z.Lit {
 	if ok {
 		return m
 	}
+	if d.placeholders {
+		m = d.c.Lit()
+		d.lits[id] = m
+		return m
+	}
 	d.errs = append(d.errs, fmt.Errorf("variable %q referenced but not provided", id))
 	return z.LitNull
 }
@@ -98,12 +242,21 @@ func (d *LitMapping) VariableOf(m z.Lit) Variable {
 	return zeroVariable{}
 }
 
+// Present returns true if the provided literal corresponds to a
+// Variable that is part of the input.
+func (d *LitMapping) Present(m z.Lit) bool {
+	_, ok := d.variables[m]
+	return ok
+}
+
 // ConstraintOf returns the constraint application corresponding to
 // the provided literal, or a zeroConstraint if no such constraint
-// exists.
+// exists. If several constraint applications are encoded by the same
+// literal, the first to be applied is returned; ConstraintsOf returns
+// all of them.
 func (d *LitMapping) ConstraintOf(m z.Lit) AppliedConstraint {
-	if a, ok := d.constraints[m]; ok {
-		return a
+	if as, ok := d.constraints[m]; ok {
+		return as[0]
 	}
 	d.errs = append(d.errs, fmt.Errorf("no constraint corresponding to %s", m))
 	return AppliedConstraint{
@@ -112,6 +265,23 @@ func (d *LitMapping) ConstraintOf(m z.Lit) AppliedConstraint {
 	}
 }
 
+// ConstraintLits returns the literals of all applied constraints, in
+// input order and without duplicates.
+func (d *LitMapping) ConstraintLits() []z.Lit {
+	var ms []z.Lit
+	seen := make(map[z.Lit]struct{})
+	for _, variable := range d.inorder {
+		for _, m := range d.applied[variable.Identifier()] {
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
 // Error returns a single error value that is an aggregation of all
 // errors encountered during a LitMapping's lifetime, or nil if there have
 // been no errors. A non-nil return value likely indicates a problem
@@ -127,34 +297,111 @@ func (d *LitMapping) Error() error {
 	return fmt.Errorf("%d errors encountered: %s", len(s), strings.Join(s, ", "))
 }
 
-// AddConstraints adds the current constraints encoded in the embedded circuit to the
-// solver g
-func (d *LitMapping) AddConstraints(g inter.S) {
-	d.c.ToCnf(g)
+// AddConstraints adds the current constraints encoded in the embedded
+// circuit to the solver g. Only the parts of the circuit that have not
+// been added by a previous call are translated to CNF, so it is safe
+// to call AddConstraints repeatedly on the same solver.
+func (d *LitMapping) AddConstraints(g inter.Adder) {
+	roots := make([]z.Lit, 0, len(d.constraints)+len(d.soft))
+	for m := range d.constraints {
+		roots = append(roots, m)
+	}
+	for _, s := range d.soft {
+		roots = append(roots, s.m)
+	}
+	d.marks, _ = d.c.CnfSince(g, d.marks, roots...)
 }
 
-func (d *LitMapping) AssumeConstraints(s inter.S) {
+// AssumeConstraints assumes that all constraints hold and that any
+// literal without a corresponding Variable is false.
+func (d *LitMapping) AssumeConstraints(s inter.Assumable) {
 	for m := range d.constraints {
 		s.Assume(m)
 	}
+	d.assumeAbsent(s)
+}
+
+// assumeAbsent assumes that every literal without a corresponding
+// Variable is false.
+func (d *LitMapping) assumeAbsent(s inter.Assumable) {
+	for _, m := range d.lits {
+		if _, ok := d.variables[m]; !ok {
+			s.Assume(m.Not())
+		}
+	}
+}
+
+// CardinalityConstrainer constructs a counter to provide cardinality
+// constraints over the provided slice of literals, using the
+// configured CardinalityEncoding. The clauses and variables for each
+// bound are only constructed, translated to CNF and taught to the
+// given inter.Adder when the bound is first requested, so requesting
+// a bound will panic if it is in a test context.
+func (d *LitMapping) CardinalityConstrainer(g inter.Adder, ms []z.Lit) counter {
+	return cnfCounter{counter: d.counter(d.c, ms), d: d, g: g}
 }
 
-// CardinalityConstrainer constructs a sorting network to provide
-// cardinality constraints over the provided slice of literals. Any
-// new clauses and variables are translated to CNF and taught to the
-// given inter.Adder, so this function will panic if it is in a test
-// context.
-func (d *LitMapping) CardinalityConstrainer(g inter.Adder, ms []z.Lit) *logic.CardSort {
-	clen := d.c.Len()
-	cs := d.c.CardSort(ms)
-	marks := make([]int8, clen, d.c.Len())
-	for i := range marks {
-		marks[i] = 1
+// cnfCounter is a counter whose bounds are taught to g when they are
+// requested.
+type cnfCounter struct {
+	counter
+	d *LitMapping
+	g inter.Adder
+}
+
+func (c cnfCounter) Leq(k int) z.Lit {
+	return c.d.cnf(c.g, c.counter.Leq(k))
+}
+
+func (c cnfCounter) Geq(k int) z.Lit {
+	return c.d.cnf(c.g, c.counter.Geq(k))
+}
+
+// cnf translates the part of the circuit rooted at m that has not yet
+// been translated to CNF, teaches it to g and returns m.
+func (d *LitMapping) cnf(g inter.Adder, m z.Lit) z.Lit {
+	d.marks, _ = d.c.CnfSince(g, d.marks, m)
+	return m
+}
+
+// counter returns a counter over ms that uses the configured
+// CardinalityEncoding.
+func (d *LitMapping) counter(c *logic.C, ms []z.Lit) counter {
+	return d.encoding.counter(c, ms)
+}
+
+// Penalties returns a slice of literals, each true in a solution
+// that violates a SoftConstraint, and the weight of each. The weights
+// are divided by their greatest common divisor, so the total weight
+// of the true literals is proportional to the total cost of a
+// solution.
+func (d *LitMapping) Penalties() ([]z.Lit, []int) {
+	var g int
+	for _, s := range d.soft {
+		g = gcd(g, s.w)
 	}
-	for w := 0; w <= cs.N(); w++ {
-		marks, _ = d.c.CnfSince(g, marks, cs.Leq(w))
+	ms := make([]z.Lit, len(d.soft))
+	ws := make([]int, len(d.soft))
+	for i, s := range d.soft {
+		ms[i], ws[i] = s.m.Not(), s.w/g
 	}
-	return cs
+	return ms, ws
+}
+
+// bound returns a literal that is true if the total weight of the
+// true literals among ms is at most k, where ws gives the weight of
+// each. Any new clauses and variables are translated to CNF and
+// taught to the given inter.Adder, so this function will panic if it
+// is in a test context.
+func (d *LitMapping) bound(g inter.Adder, ms []z.Lit, ws []int, k int) z.Lit {
+	return d.cnf(g, pbLeq(d.c, ms, ws, k))
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
 }
 
 // AnchorIdentifiers returns a slice containing the Identifiers of
@@ -173,7 +420,7 @@ func (d *LitMapping) AnchorIdentifiers() []Identifier {
 	return ids
 }
 
-func (d *LitMapping) Variables(g inter.S) []Variable {
+func (d *LitMapping) Variables(g inter.Model) []Variable {
 	var result []Variable
 	for _, i := range d.inorder {
 		if g.Value(d.LitOf(i.Identifier())) {
@@ -196,12 +443,29 @@ func (d *LitMapping) Lits(dst []z.Lit) []z.Lit {
 }
 
 func (d *LitMapping) Conflicts(g inter.Assumable) []AppliedConstraint {
+	return d.ConstraintsOf(d.ConflictLits(g))
+}
+
+// ConflictLits returns the literals of the constraints among the
+// failed assumptions of the last unsatisfiable result of g.
+func (d *LitMapping) ConflictLits(g inter.Assumable) []z.Lit {
 	whys := g.Why(nil)
-	as := make([]AppliedConstraint, 0, len(whys))
+	ms := make([]z.Lit, 0, len(whys))
 	for _, why := range whys {
-		if a, ok := d.constraints[why]; ok {
-			as = append(as, a)
+		if _, ok := d.constraints[why]; ok {
+			ms = append(ms, why)
 		}
 	}
+	return ms
+}
+
+// ConstraintsOf returns every constraint application corresponding
+// to the provided literals, skipping any literals that do not
+// correspond to a constraint.
+func (d *LitMapping) ConstraintsOf(ms []z.Lit) []AppliedConstraint {
+	as := make([]AppliedConstraint, 0, len(ms))
+	for _, m := range ms {
+		as = append(as, d.constraints[m]...)
+	}
 	return as
 }
@@ -0,0 +1,87 @@
+package sat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolvePriorSelection(t *testing.T) {
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Prior     []Identifier
+		Installed []Identifier
+	}{
+		{
+			Name: "prior selection retained despite preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x2", "x1")),
+				variable("x1"),
+				variable("x2"),
+			},
+			Prior:     []Identifier{"a", "x1"},
+			Installed: []Identifier{"a", "x1"},
+		},
+		{
+			Name: "prior variable removed when necessary",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x1", "x2", "x3")),
+				variable("x1", Prohibited()),
+				variable("x2"),
+				variable("x3"),
+			},
+			Prior:     []Identifier{"a", "x1"},
+			Installed: []Identifier{"a", "x2"},
+		},
+		{
+			Name: "fewest additions",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("p", "q", "r")),
+				variable("p", Dependency("s")),
+				variable("q"),
+				variable("r"),
+				variable("s"),
+			},
+			Prior:     []Identifier{},
+			Installed: []Identifier{"a", "q"},
+		},
+		{
+			Name: "unselected prior variables retained",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b", Dependency("c")),
+				variable("c"),
+			},
+			Prior:     []Identifier{"b", "c", "missing"},
+			Installed: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "soft constraints take precedence",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x1", "x2")),
+				variable("x1", Penalty(1)),
+				variable("x2"),
+			},
+			Prior:     []Identifier{"a", "x1"},
+			Installed: []Identifier{"a", "x2"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var prior []Variable
+			for _, id := range tt.Prior {
+				prior = append(prior, variable(id))
+			}
+			for _, options := range [][]Option{nil, {WithDecomposition()}, {WithPruning()}} {
+				s, err := NewSolver(append(options, WithInput(tt.Variables), WithPriorSelection(prior))...)
+				if !assert.NoError(t, err) {
+					return
+				}
+				installed, err := s.Solve(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, tt.Installed, identifiers(installed))
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package sat
+
+import "fmt"
+
+// PrunedVariable is returned by SolveUnder when asked to make an
+// assumption about a Variable that is part of the input but was left
+// out by WithPruning.
+type PrunedVariable Identifier
+
+func (e PrunedVariable) Error() string {
+	return fmt.Sprintf("variable %q was pruned from the input", Identifier(e))
+}
+
+// prune returns the Variables that are reachable from roots through
+// the references of their constraints, in input order. Roots are
+// Variables that are anchors, that have a constraint that could
+// affect a solution even if they are not selected, that have a
+// SoftConstraint, or that are identified by prior. Every solution of
+// the result, extended with none of the remaining Variables, is a
+// solution of the full input of the same cost, and retains as many
+// prior Variables.
+//
+// If several Variables share an Identifier, the input is returned
+// unchanged so that the duplicate can be reported.
+func prune(vars []Variable, prior map[Identifier]struct{}) []Variable {
+	index := make(map[Identifier]Variable, len(vars))
+	for _, v := range vars {
+		if _, ok := index[v.Identifier()]; ok {
+			return vars
+		}
+		index[v.Identifier()] = v
+	}
+
+	reached := make(map[Identifier]struct{}, len(vars))
+	var queue []Variable
+	for _, v := range vars {
+		if _, ok := prior[v.Identifier()]; ok || root(v) {
+			reached[v.Identifier()] = struct{}{}
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, c := range v.Constraints() {
+			for _, id := range references(c) {
+				if _, ok := reached[id]; ok {
+					continue
+				}
+				next, ok := index[id]
+				if !ok {
+					continue
+				}
+				reached[id] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	result := make([]Variable, 0, len(reached))
+	for _, v := range vars {
+		if _, ok := reached[v.Identifier()]; ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// root returns true if the provided Variable must be encoded
+// regardless of whether or not it is reachable from an anchor.
+func root(v Variable) bool {
+	for _, c := range v.Constraints() {
+		if c.Anchor() {
+			return true
+		}
+		switch c.(type) {
+		case prohibited, dependency, conflict, zeroConstraint:
+			// These hold whenever their subject is
+			// not selected.
+		default:
+			// Including SoftConstraints, which
+			// determine the cost of a solution and
+			// may be explained.
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Reached   []Identifier
+	}{
+		{
+			Name: "no anchors",
+			Variables: []Variable{
+				variable("a", Dependency("b")),
+				variable("b"),
+			},
+		},
+		{
+			Name: "dependencies are followed",
+			Variables: []Variable{
+				variable("x", Dependency("a")),
+				variable("a", Mandatory(), Dependency("b", "c")),
+				variable("b", Dependency("d")),
+				variable("c"),
+				variable("d"),
+				variable("e", Dependency("a")),
+			},
+			Reached: []Identifier{"a", "b", "c", "d"},
+		},
+		{
+			Name: "conflicts and cardinality constraints are followed",
+			Variables: []Variable{
+				variable("a", Mandatory(), Conflict("b"), AtMost(1, "c", "d")),
+				variable("b"),
+				variable("c"),
+				variable("d", Dependency("e")),
+				variable("e"),
+				variable("f", Conflict("a")),
+			},
+			Reached: []Identifier{"a", "b", "c", "d", "e"},
+		},
+		{
+			Name: "unconditional constraints are roots",
+			Variables: []Variable{
+				variable("a", AtLeast(1, "b")),
+				variable("b", Dependency("c")),
+				variable("c", Prohibited()),
+				variable("d", Conflict("c")),
+			},
+			Reached: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "soft constraints are roots",
+			Variables: []Variable{
+				variable("a", Prefer(1), Dependency("b")),
+				variable("b"),
+				variable("c", Penalty(1)),
+				variable("d", Conflict("c")),
+			},
+			Reached: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "composed constraints are roots",
+			Variables: []Variable{
+				variable("a", Implies(Selected("b"), Not(Selected("c")))),
+				variable("b"),
+				variable("c"),
+				variable("d"),
+			},
+			Reached: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "missing references are ignored",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("missing", "b")),
+				variable("b"),
+			},
+			Reached: []Identifier{"a", "b"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var reached []Identifier
+			for _, v := range prune(tt.Variables, nil) {
+				reached = append(reached, v.Identifier())
+			}
+			assert.Equal(t, tt.Reached, reached)
+		})
+	}
+}
+
+func TestPruneDuplicateIdentifier(t *testing.T) {
+	_, err := NewSolver(WithPruning(), WithInput([]Variable{
+		variable("a"),
+		variable("a"),
+	}))
+	assert.Equal(t, DuplicateIdentifier("a"), err)
+}
+
+func TestSolvePruningMatchesUnpruned(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	for i := 0; i < 200; i++ {
+		vars := randomVariables(r, 1+r.Intn(12))
+
+		full, err := NewSolver(WithInput(vars))
+		if !assert.NoError(t, err) {
+			return
+		}
+		expected, expectedErr := full.Solve(context.Background())
+
+		pruned, err := NewSolver(WithInput(vars), WithPruning())
+		if !assert.NoError(t, err) {
+			return
+		}
+		actual, err := pruned.Solve(context.Background())
+
+		if expectedErr != nil {
+			assert.True(t, errors.As(err, &NotSatisfiable{}), "expected %v to be unsatisfiable, got %v", identifiers(vars), err)
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, Verify(vars, actual))
+		assert.Equal(t, len(expected), len(actual))
+	}
+}
+
+func TestSolvePruningPreferred(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithPruning(), WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b", Prefer(1), Dependency("c")),
+		variable("c"),
+	}))
+	assert.NoError(err)
+	installed, err := s.Solve(context.Background())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "b", "c"}, identifiers(installed))
+
+	explanation, err := s.Explain(context.Background(), installed, "b")
+	assert.NoError(err)
+	assert.True(explanation.Selected)
+}
+
+func TestExplainPruned(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithPruning(), WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b", Dependency("c")),
+		variable("c"),
+	}))
+	assert.NoError(err)
+	installed, err := s.Solve(context.Background())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a"}, identifiers(installed))
+
+	explanation, err := s.Explain(context.Background(), installed, "b")
+	assert.NoError(err)
+	assert.Equal(Explanation{Identifier: "b", Pruned: true}, explanation)
+	assert.Equal("b is not selected because it cannot be reached from any anchor", explanation.String())
+
+	_, err = s.Explain(context.Background(), installed, "missing")
+	assert.EqualError(err, `no variable with identifier "missing"`)
+}
+
+func TestSessionRejectsPruning(t *testing.T) {
+	_, err := NewSession(WithPruning())
+	assert.Error(t, err)
+}
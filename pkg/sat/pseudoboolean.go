@@ -0,0 +1,99 @@
+package sat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// pbLeq returns a literal that is true exactly when the sum of the
+// weights of the true literals among ms is at most k. The weights are
+// given by ws, in the same order as ms, and may be negative.
+//
+// The constraint is encoded as a reduced ordered decision diagram,
+// which is built by deciding each literal in turn, heaviest first.
+// Every node records the interval of bounds for which it is correct,
+// so that the nodes for bounds that are equivalent with respect to
+// the remaining literals are shared. This is the BDD-based encoding
+// described by Abío et al. in "A New Look at BDDs for Pseudo-Boolean
+// Constraints" (JAIR, 2012).
+func pbLeq(c *logic.C, ms []z.Lit, ws []int, k int) z.Lit {
+	type term struct {
+		m z.Lit
+		w int
+	}
+	terms := make([]term, 0, len(ms))
+	for i, m := range ms {
+		switch w := ws[i]; {
+		case w > 0:
+			terms = append(terms, term{m: m, w: w})
+		case w < 0:
+			// w*m = w + (-w)*(not m)
+			terms = append(terms, term{m: m.Not(), w: -w})
+			k -= w
+		}
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].w > terms[j].w
+	})
+
+	// rest[i] is the total weight of terms[i:].
+	rest := make([]int, len(terms)+1)
+	for i := len(terms) - 1; i >= 0; i-- {
+		rest[i] = rest[i+1] + terms[i].w
+	}
+
+	type node struct {
+		m      z.Lit
+		lo, hi int // the bounds for which m is correct
+	}
+	memo := make([][]node, len(terms))
+	var build func(i, k int) node
+	build = func(i, k int) node {
+		if k < 0 {
+			return node{m: c.F, lo: math.MinInt, hi: -1}
+		}
+		if k >= rest[i] {
+			return node{m: c.T, lo: rest[i], hi: math.MaxInt}
+		}
+		for _, n := range memo[i] {
+			if n.lo <= k && k <= n.hi {
+				return n
+			}
+		}
+		t := build(i+1, k-terms[i].w)
+		e := build(i+1, k)
+		n := node{
+			lo: e.lo,
+			hi: e.hi,
+		}
+		if lo := addSaturating(t.lo, terms[i].w); lo > n.lo {
+			n.lo = lo
+		}
+		if hi := addSaturating(t.hi, terms[i].w); hi < n.hi {
+			n.hi = hi
+		}
+		if t.m == e.m {
+			n.m = t.m
+		} else {
+			n.m = c.Choice(terms[i].m, t.m, e.m)
+		}
+		memo[i] = append(memo[i], n)
+		return n
+	}
+	return build(0, k).m
+}
+
+// addSaturating returns a + b, saturating at the bounds of int instead of
+// overflowing.
+func addSaturating(a, b int) int {
+	switch {
+	case b > 0 && a > math.MaxInt-b:
+		return math.MaxInt
+	case b < 0 && a < math.MinInt-b:
+		return math.MinInt
+	}
+	return a + b
+}
@@ -0,0 +1,165 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBLeq(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	for i := 0; i < 500; i++ {
+		n := r.Intn(7)
+		c := logic.NewC()
+		ms := make([]z.Lit, n)
+		ws := make([]int, n)
+		var total int
+		for j := range ms {
+			ms[j] = c.Lit()
+			if r.Intn(4) == 0 {
+				// Literals may appear more than once,
+				// and in both polarities.
+				ms[j] = ms[r.Intn(j+1)]
+				if r.Intn(2) == 0 {
+					ms[j] = ms[j].Not()
+				}
+			}
+			ws[j] = r.Intn(21) - 5
+			if ws[j] > 0 {
+				total += ws[j]
+			}
+		}
+		k := r.Intn(total+7) - 3
+		m := pbLeq(c, ms, ws, k)
+
+		inputs := c.InPos(nil)
+		for mask := 0; mask < 1<<len(inputs); mask++ {
+			vs := make([]bool, c.Len())
+			for b, in := range inputs {
+				vs[in] = mask&(1<<b) != 0
+			}
+			c.Eval(vs)
+			value := func(m z.Lit) bool {
+				return vs[m.Var()] == m.IsPos()
+			}
+			var sum int
+			for j, each := range ms {
+				if value(each) {
+					sum += ws[j]
+				}
+			}
+			if value(m) != (sum <= k) {
+				t.Fatalf("weights %v, bound %d: encoding is %t for sum %d", ws, k, value(m), sum)
+			}
+		}
+	}
+}
+
+func TestWeightedString(t *testing.T) {
+	terms := []Weighted{{Identifier: "x", Weight: 4}, {Identifier: "y", Weight: 8}}
+	assert.Equal(t, "a permits a total weight of at most 10 among x (weight 4), y (weight 8)", WeightedAtMost(10, terms...).String("a"))
+	assert.Equal(t, "a requires a total weight of at least 10 among x (weight 4), y (weight 8)", WeightedAtLeast(10, terms...).String("a"))
+}
+
+func TestSolveWeighted(t *testing.T) {
+	cpu := func(ids ...Identifier) []Weighted {
+		weights := map[Identifier]int{"x": 500, "y": 1500, "z": 2000}
+		var terms []Weighted
+		for _, id := range ids {
+			terms = append(terms, Weighted{Identifier: id, Weight: weights[id]})
+		}
+		return terms
+	}
+	budget := WeightedAtMost(2500, cpu("x", "y", "z")...)
+
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Installed []Identifier
+		Conflicts []Constraint
+	}{
+		{
+			Name: "within budget",
+			Variables: []Variable{
+				variable("pool", Mandatory(), budget),
+				variable("a", Mandatory(), Dependency("z", "y")),
+				variable("b", Mandatory(), Dependency("x")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"pool", "a", "b", "x", "z"},
+		},
+		{
+			Name: "preferred candidate exceeds budget",
+			Variables: []Variable{
+				variable("pool", Mandatory(), budget),
+				variable("a", Mandatory(), Dependency("z", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"pool", "a", "b", "y"},
+		},
+		{
+			Name: "over budget",
+			Variables: []Variable{
+				variable("pool", Mandatory(), budget),
+				variable("a", Mandatory(), Dependency("z")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Conflicts: []Constraint{budget, Mandatory(), Dependency("z"), Mandatory(), Dependency("y")},
+		},
+		{
+			Name: "minimum weight",
+			Variables: []Variable{
+				variable("a", Mandatory(), WeightedAtLeast(2000, cpu("x", "y", "z")...), AtMost(1, "y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "z"},
+		},
+		{
+			Name: "minimum weight with excluded candidate",
+			Variables: []Variable{
+				variable("a", Mandatory(), WeightedAtLeast(2000, cpu("x", "y", "z")...), Conflict("z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "x", "y"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			s, err := NewSolver(WithInput(tt.Variables), WithMinimalConflicts())
+			if !assert.NoError(t, err) {
+				return
+			}
+			installed, err := s.Solve(context.TODO())
+			var conflicts NotSatisfiable
+			if tt.Conflicts != nil {
+				if assert.True(t, errors.As(err, &conflicts), "unexpected result %v", err) {
+					var cs []Constraint
+					for _, a := range conflicts {
+						cs = append(cs, a.Constraint)
+					}
+					assert.ElementsMatch(t, tt.Conflicts, cs)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Installed, identifiers(installed))
+			assert.Empty(t, Verify(tt.Variables, installed))
+		})
+	}
+}
@@ -3,7 +3,6 @@ package sat
 import (
 	"context"
 
-	"github.com/go-air/gini/inter"
 	"github.com/go-air/gini/z"
 )
 
@@ -21,14 +20,18 @@ type guess struct {
 }
 
 type search struct {
-	s                      inter.S
+	s                      Backend
 	lits                   *LitMapping
 	assumptions            map[z.Lit]struct{} // set of assumed lits - duplicates guess stack - for fast lookup
 	guesses                []guess            // stack of assumed guesses
 	headChoice, tailChoice *choice            // deque of unmade choices
 	tracer                 Tracer
+	events                 emitter
+	stats                  *Stats // if not nil, counts guesses and backtracks
+	strategy               SearchStrategy
 	result                 int
 	buffer                 []z.Lit
+	model                  map[z.Lit]bool // values of all Variable literals in the last satisfying assignment
 }
 
 func (h *search) PushGuess() {
@@ -38,6 +41,11 @@ func (h *search) PushGuess() {
 		index:      c.index,
 		candidates: c.candidates,
 	}
+	// Candidates that are not part of the input can never be
+	// selected, so there is no point in guessing them.
+	for g.index < len(g.candidates) && !h.lits.Present(g.candidates[g.index]) {
+		g.index++
+	}
 	if g.index < len(g.candidates) {
 		g.m = g.candidates[g.index]
 	}
@@ -57,14 +65,36 @@ func (h *search) PushGuess() {
 	}
 
 	variable := h.lits.VariableOf(g.m)
+	h.events.emit(Event{Kind: EventGuessPushed, Depth: len(h.guesses), Variable: variable.Identifier()})
+	var children []choice
 	for _, constraint := range variable.Constraints() {
 		var ms []z.Lit
+		ids := make(map[z.Lit]Identifier)
 		for _, dependency := range constraint.Order() {
-			ms = append(ms, h.lits.LitOf(dependency))
+			m := h.lits.LitOf(dependency)
+			ms = append(ms, m)
+			ids[m] = dependency
 		}
 		if len(ms) > 0 {
-			h.guesses[len(h.guesses)-1].children++
-			h.PushChoiceBack(choice{candidates: ms})
+			candidates := h.strategy.order(h.lits, variable, ms)
+			children = append(children, choice{candidates: candidates})
+			order := make([]Identifier, len(candidates))
+			for i, m := range candidates {
+				order[i] = ids[m]
+			}
+			h.events.emit(Event{Kind: EventChoiceEnqueued, Depth: len(h.guesses), Candidates: order})
+		}
+	}
+	h.guesses[len(h.guesses)-1].children = len(children)
+	if h.strategy.depthFirst {
+		// Push in reverse so that the choices are made in the
+		// order their constraints were applied.
+		for i := len(children) - 1; i >= 0; i-- {
+			h.PushChoiceFront(children[i])
+		}
+	} else {
+		for _, c := range children {
+			h.PushChoiceBack(c)
 		}
 	}
 
@@ -72,8 +102,12 @@ func (h *search) PushGuess() {
 		h.assumptions = make(map[z.Lit]struct{})
 	}
 	h.assumptions[g.m] = struct{}{}
+	if h.stats != nil {
+		h.stats.Guesses++
+	}
 	h.s.Assume(g.m)
 	h.result, h.buffer = h.s.Test(h.buffer)
+	h.events.emit(Event{Kind: EventTestOutcome, Depth: len(h.guesses), Outcome: outcomeString(h.result)})
 }
 
 func (h *search) PopGuess() {
@@ -82,10 +116,20 @@ func (h *search) PopGuess() {
 	if g.m != z.LitNull {
 		delete(h.assumptions, g.m)
 		h.result = h.s.Untest()
+		if h.events.enabled() {
+			h.events.emit(Event{Kind: EventGuessPopped, Depth: len(h.guesses), Variable: h.lits.VariableOf(g.m).Identifier()})
+		}
 	}
+	// The choices introduced by this guess are all that remain at
+	// the end of the deque they were pushed to, since the guesses
+	// made after it have already been popped.
 	for g.children > 0 {
 		g.children--
-		h.PopChoiceBack()
+		if h.strategy.depthFirst {
+			h.PopChoiceFront()
+		} else {
+			h.PopChoiceBack()
+		}
 	}
 	c := choice{
 		index:      g.index,
@@ -156,16 +200,28 @@ func (h *search) Lits() []z.Lit {
 }
 
 func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.Lit]struct{}) {
+	h.events = newEmitter(h.tracer)
 	for _, m := range anchors {
 		h.PushChoiceBack(choice{candidates: []z.Lit{m}})
+		if h.events.enabled() {
+			h.events.emit(Event{Kind: EventChoiceEnqueued, Candidates: []Identifier{h.lits.VariableOf(m).Identifier()}})
+		}
 	}
 
 	for {
+		// Give up, keeping the guesses made so far, as soon as
+		// the caller is no longer interested in a result.
+		if ctx.Err() != nil {
+			h.result = unknown
+			break
+		}
+
 		// Need to have a definitive result once all choices
 		// have been made to decide whether to end or
 		// backtrack.
 		if h.headChoice == nil && h.result == unknown {
-			h.result = h.s.Solve()
+			h.result = solveContext(ctx, h.s)
+			h.events.emit(Event{Kind: EventTestOutcome, Depth: len(h.guesses), Outcome: outcomeString(h.result)})
 		}
 
 		// Backtrack if possible, otherwise end.
@@ -174,6 +230,17 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 			if len(h.guesses) == 0 {
 				break
 			}
+			if h.events.enabled() {
+				h.events.emit(Event{
+					Kind:      EventBacktrack,
+					Depth:     len(h.guesses),
+					Variables: identifiersOf(h.Variables()),
+					Conflicts: NotSatisfiable(h.Conflicts()).Descriptions(),
+				})
+			}
+			if h.stats != nil {
+				h.stats.Backtracks++
+			}
 			h.PopGuess()
 			continue
 		}
@@ -194,6 +261,15 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 	}
 	result := h.Result()
 
+	// Record the model before it is discarded by leaving the
+	// test scopes that were opened during search.
+	if result == satisfiable {
+		h.model = make(map[z.Lit]bool, len(h.lits.inorder))
+		for _, m := range h.lits.Lits(nil) {
+			h.model[m] = h.s.Value(m)
+		}
+	}
+
 	// Go back to the initial test scope.
 	for len(h.guesses) > 0 {
 		h.PopGuess()
@@ -202,6 +278,15 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 	return result, lits, set
 }
 
+// Value returns the value of the literal of a Variable in the
+// satisfying assignment found by the last call to Do.
+func (h *search) Value(m z.Lit) bool {
+	if !m.IsPos() {
+		return !h.model[m.Not()]
+	}
+	return h.model[m]
+}
+
 func (h *search) Variables() []Variable {
 	result := make([]Variable, 0, len(h.guesses))
 	for _, g := range h.guesses {
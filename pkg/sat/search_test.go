@@ -104,3 +104,52 @@ func TestSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchCancellation(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var s FakeS
+	s.TestStub = func(dst []z.Lit) (int, []z.Lit) {
+		// Cancel once the second guess has been tested.
+		if s.TestCallCount() == 2 {
+			cancel()
+		}
+		return 0, nil
+	}
+
+	var depth int
+	counter := &TestScopeCounter{depth: &depth, S: &s}
+
+	lits, err := newLitMapping([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("b", Mandatory(), Dependency("y")),
+		variable("x"),
+		variable("y"),
+	})
+	assert.NoError(err)
+	h := search{
+		s:      counter,
+		lits:   lits,
+		tracer: DefaultTracer{},
+	}
+
+	var anchors []z.Lit
+	for _, id := range h.lits.AnchorIdentifiers() {
+		anchors = append(anchors, h.lits.LitOf(id))
+	}
+
+	result, ms, _ := h.Do(ctx, anchors)
+
+	assert.Equal(unknown, result)
+	var ids []Identifier
+	for _, m := range ms {
+		ids = append(ids, lits.VariableOf(m).Identifier())
+	}
+	assert.Equal([]Identifier{"a", "b"}, ids)
+	assert.Equal(2, s.TestCallCount())
+	assert.Equal(0, s.SolveCallCount())
+	assert.Equal(0, depth)
+}
@@ -0,0 +1,63 @@
+package sat
+
+import (
+	"errors"
+)
+
+// Session is a Solver whose input can be changed between calls to
+// Solve. The translation of each Variable and its constraints to the
+// underlying SAT formula, as well as anything learned by the
+// underlying solver, is retained for the lifetime of the Session, so
+// solving again after a small change to the input costs much less
+// than constructing a new Solver.
+//
+// Constraints are never taught to the underlying solver as
+// unconditional clauses. Instead, the literal encoding each
+// constraint acts as its activation literal, and is assumed only
+// while the Variable it applies to is part of the input. Identifiers
+// that are referenced by a constraint but are not part of the input
+// identify Variables that can never be selected, rather than
+// causing Solve to fail.
+//
+// A Session is not safe for concurrent use.
+type Session struct {
+	*solver
+}
+
+var _ Solver = &Session{}
+
+// NewSession returns a new Session configured with the provided
+// Options. Any Variables provided using WithInput form the initial
+// input.
+func NewSession(options ...Option) (*Session, error) {
+	s, err := newSolver(append(options, func(s *solver) error {
+		s.placeholders = true
+		// The components of the input change along with
+		// it, so it is always solved as a whole.
+		s.decompose = false
+		return nil
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	if s.prune {
+		return nil, errors.New("pruning is not supported by sessions")
+	}
+	return &Session{solver: s}, nil
+}
+
+// Add adds the provided Variables to the input. It returns a
+// DuplicateIdentifier error, without changing the input, if any of
+// them shares an Identifier with another Variable in the input. To
+// change the constraints applied to a Variable, Remove it before
+// adding its replacement.
+func (s *Session) Add(variables ...Variable) error {
+	return s.litMap.add(variables)
+}
+
+// Remove removes the Variables with the provided Identifiers, along
+// with the constraints applied to them, from the input. Identifiers
+// that are not part of the input are ignored.
+func (s *Session) Remove(ids ...Identifier) {
+	s.litMap.remove(ids)
+}
@@ -0,0 +1,155 @@
+package sat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSession(WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x"),
+		variable("y"),
+	}))
+	assert.NoError(err)
+
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "x"}, identifiers(installed))
+
+	// A new Variable conflicting with the preferred dependency.
+	assert.NoError(s.Add(variable("b", Mandatory(), Conflict("x"))))
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "y", "b"}, identifiers(installed))
+
+	// Removing it restores the original solution.
+	s.Remove("b")
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "x"}, identifiers(installed))
+
+	// Removing a dependency leaves only the alternative.
+	s.Remove("x")
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "y"}, identifiers(installed))
+
+	// A removed Variable can be added again with different
+	// constraints.
+	assert.NoError(s.Add(variable("x", Prohibited())))
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "y"}, identifiers(installed))
+
+	s.Remove("y")
+	_, err = s.Solve(context.TODO())
+	assert.ElementsMatch(NotSatisfiable{
+		{
+			Variable:   variable("a", Mandatory(), Dependency("x", "y")),
+			Constraint: Mandatory(),
+		},
+		{
+			Variable:   variable("a", Mandatory(), Dependency("x", "y")),
+			Constraint: Dependency("x", "y"),
+		},
+		{
+			Variable:   variable("x", Prohibited()),
+			Constraint: Prohibited(),
+		},
+	}, err)
+}
+
+func TestSessionMissingReference(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSession(WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("b")),
+	}))
+	assert.NoError(err)
+
+	_, err = s.Solve(context.TODO())
+	assert.ElementsMatch(NotSatisfiable{
+		{
+			Variable:   variable("a", Mandatory(), Dependency("b")),
+			Constraint: Mandatory(),
+		},
+		{
+			Variable:   variable("a", Mandatory(), Dependency("b")),
+			Constraint: Dependency("b"),
+		},
+	}, err)
+
+	assert.NoError(s.Add(variable("b")))
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "b"}, identifiers(installed))
+}
+
+func TestSessionDuplicateIdentifier(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSession(WithInput([]Variable{variable("a")}))
+	assert.NoError(err)
+
+	assert.Equal(DuplicateIdentifier("a"), s.Add(variable("b"), variable("a")))
+	assert.Equal(DuplicateIdentifier("b"), s.Add(variable("b"), variable("b")))
+
+	// Nothing was added by the failed calls.
+	assert.NoError(s.Add(variable("b", Mandatory())))
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"b"}, identifiers(installed))
+}
+
+func TestSessionSharedConstraint(t *testing.T) {
+	assert := assert.New(t)
+
+	// Both conflicts are encoded by the same literal.
+	s, err := NewSession(WithInput([]Variable{
+		variable("a", Mandatory(), Conflict("b")),
+		variable("b", Conflict("a")),
+		variable("c", Mandatory(), Dependency("b", "d")),
+		variable("d"),
+	}))
+	assert.NoError(err)
+
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "c", "d"}, identifiers(installed))
+
+	// The conflict still applies after one side is removed and
+	// added again without it.
+	s.Remove("a")
+	assert.NoError(s.Add(variable("a", Mandatory())))
+	installed, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"c", "d", "a"}, identifiers(installed))
+}
+
+func TestSessionReusesEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSession(WithInput(BenchmarkInput))
+	assert.NoError(err)
+
+	first, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	size := s.litMap.c.Len()
+
+	second, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal(identifiers(first), identifiers(second))
+	assert.Equal(size, s.litMap.c.Len())
+
+	// The result matches that of a new Solver.
+	fresh, err := NewSolver(WithInput(BenchmarkInput))
+	assert.NoError(err)
+	expected, err := fresh.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal(identifiers(expected), identifiers(second))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-air/gini"
 	"github.com/go-air/gini/inter"
@@ -13,6 +14,31 @@ import (
 
 var ErrIncomplete = errors.New("cancelled before a solution could be found")
 
+// Incomplete is returned by Solve when the provided Context is
+// cancelled or times out before a solution could be found. It
+// matches ErrIncomplete when tested with errors.Is.
+type Incomplete struct {
+	// Variables contains the Variables that had been selected
+	// when the search stopped. If the search had already found a
+	// solution and was interrupted while minimizing it, this is
+	// that complete, but possibly non-minimal, solution.
+	Variables []Variable
+	// Err is the error reported by the Context.
+	Err error
+}
+
+func (e Incomplete) Error() string {
+	return fmt.Sprintf("%s (%s with %d variables selected)", ErrIncomplete, e.Err, len(e.Variables))
+}
+
+func (e Incomplete) Is(target error) bool {
+	return target == ErrIncomplete
+}
+
+func (e Incomplete) Unwrap() error {
+	return e.Err
+}
+
 // NotSatisfiable is an error composed of a minimal set of applied
 // constraints that is sufficient to make a solution impossible.
 type NotSatisfiable []AppliedConstraint
@@ -31,13 +57,70 @@ func (e NotSatisfiable) Error() string {
 
 type Solver interface {
 	Solve(context.Context) ([]Variable, error)
+	SolveAll(ctx context.Context, limit int) ([][]Variable, error)
+	SolveAllOptimal(ctx context.Context, limit int) ([][]Variable, error)
+	CorrectionSets(ctx context.Context, limit int) ([]CorrectionSet, error)
+	Explain(ctx context.Context, selection []Variable, id Identifier) (Explanation, error)
+	SolveUnder(ctx context.Context, assume map[Identifier]bool) ([]Variable, error)
 }
 
 type solver struct {
-	g      inter.S
+	g      Backend
+	input  []Variable
 	litMap *LitMapping
 	tracer Tracer
 	buffer []z.Lit
+	// bounds are literals restricting solutions to those that are
+	// optimal with respect to soft constraints; they are assumed
+	// along with all constraints
+	bounds []z.Lit
+	// guards are activation literals of clauses that have been
+	// added temporarily; they are assumed along with all
+	// constraints while the clauses they guard should apply
+	guards []z.Lit
+	// minimalConflicts enables reduction of NotSatisfiable errors
+	// to minimal unsatisfiable subsets of constraints
+	minimalConflicts bool
+	// nonRelaxable reports whether an applied constraint must
+	// never appear in a correction set
+	nonRelaxable func(AppliedConstraint) bool
+	// suggestions is the maximum number of correction sets to
+	// include in errors when no solution exists
+	suggestions int
+	// violations counts the violated relaxable constraints while
+	// looking for correction sets
+	violations struct {
+		ms []z.Lit
+		cs counter
+	}
+	// stats accumulates statistics about the current call to
+	// Solve, which are copied to statsOut, if set, on return
+	stats    Stats
+	statsOut *Stats
+	// mapping is the time spent mapping the input to literals
+	// when the solver was constructed, which is attributed to
+	// the first call to Solve
+	mapping time.Duration
+	// placeholders permits constraints to reference Identifiers
+	// that are not part of the input
+	placeholders bool
+	// prune restricts the input to Variables reachable from its
+	// roots before encoding it
+	prune bool
+	// pruned holds the Identifiers of the input Variables that
+	// were left out by pruning
+	pruned map[Identifier]struct{}
+	// decompose solves independent parts of the input separately
+	decompose bool
+	strategy  SearchStrategy
+	// encoding determines how cardinality constraints are encoded
+	encoding CardinalityEncoding
+	// prior, if not nil, holds the Identifiers of a previous
+	// selection that solutions should change as little as possible
+	prior map[Identifier]struct{}
+	// hypotheses holds the literals of Variables assumed to be
+	// selected by SolveUnder, which are treated as anchors
+	hypotheses []z.Lit
 }
 
 const (
@@ -58,68 +141,534 @@ func (s *solver) Solve(ctx context.Context) (result []Variable, err error) {
 			result = nil
 			err = derr
 		}
+		s.traceResult(result, err)
+		s.finishStats()
 	}()
+	s.stats = Stats{Clauses: s.stats.Clauses, Encode: s.mapping}
+	s.mapping = 0
+
+	if s.decompose {
+		if parts := components(s.litMap.inorder); len(parts) > 1 {
+			return s.solveComponents(ctx, parts)
+		}
+	}
 
 	// teach all constraints to the solver
+	start := time.Now()
 	s.litMap.AddConstraints(s.g)
+	s.stats.Encode += time.Since(start)
 
 	// collect literals of all mandatory variables to assume as a baseline
-	anchors := s.litMap.AnchorIdentifiers()
-	assumptions := make([]z.Lit, len(anchors))
-	for i := range s.litMap.AnchorIdentifiers() {
-		assumptions[i] = s.litMap.LitOf(anchors[i])
+	assumptions := s.anchors()
+	s.stats.Anchors = len(assumptions)
+
+	if err := ctx.Err(); err != nil {
+		return nil, Incomplete{Err: err}
+	}
+
+	// restrict the search to solutions of minimal cost, so that
+	// preferences are only taken into account between solutions
+	// that violate soft constraints equally
+	start = time.Now()
+	err = s.minimizeCost(ctx, assumptions)
+	s.stats.Minimize += time.Since(start)
+	if err != nil {
+		return nil, err
 	}
 
 	// assume that all constraints hold
-	s.litMap.AssumeConstraints(s.g)
+	s.assumeConstraints()
 	s.g.Assume(assumptions...)
 
 	var aset map[z.Lit]struct{}
+	var model inter.Model = s.g
 	// push a new test scope with the baseline assumptions, to prevent them from being cleared during search
+	start = time.Now()
 	outcome, _ := s.g.Test(nil)
 	if outcome != satisfiable && outcome != unsatisfiable {
 		// searcher for solutions in input Order, so that preferences
 		// can be taken into acount (i.e. prefer one catalog to another)
-		outcome, assumptions, aset = (&search{s: s.g, lits: s.litMap, tracer: s.tracer}).Do(context.Background(), assumptions)
+		h := &search{s: s.g, lits: s.litMap, tracer: s.tracer, stats: &s.stats, strategy: s.strategy}
+		outcome, assumptions, aset = h.Do(ctx, assumptions)
+		model = h
 	}
+	s.stats.Search += time.Since(start)
 	switch outcome {
 	case satisfiable:
+		selection := s.litMap.Variables(model)
 		s.buffer = s.litMap.Lits(s.buffer)
 		var extras, excluded []z.Lit
 		for _, m := range s.buffer {
 			if _, ok := aset[m]; ok {
 				continue
 			}
-			if !s.g.Value(m) {
+			if !model.Value(m) {
 				excluded = append(excluded, m.Not())
 				continue
 			}
 			extras = append(extras, m)
 		}
 		s.g.Untest()
+		start = time.Now()
+		defer func() {
+			s.stats.Minimize += time.Since(start)
+		}()
 		cs := s.litMap.CardinalityConstrainer(s.g, extras)
-		s.g.Assume(assumptions...)
-		s.g.Assume(excluded...)
-		s.litMap.AssumeConstraints(s.g)
-		_, s.buffer = s.g.Test(s.buffer)
 		for w := 0; w <= cs.N(); w++ {
-			s.g.Assume(cs.Leq(w))
-			if s.g.Solve() == satisfiable {
+			if err := ctx.Err(); err != nil {
+				return nil, Incomplete{Variables: selection, Err: err}
+			}
+			bound := cs.Leq(w)
+			s.g.Assume(assumptions...)
+			s.g.Assume(excluded...)
+			s.assumeConstraints()
+			s.g.Assume(bound)
+			s.stats.MinimizationIterations++
+			outcome := solveContext(ctx, s.g)
+			w := w
+			s.events().emit(Event{Kind: EventMinimizationStep, Bound: &w, Outcome: outcomeString(outcome)})
+			switch outcome {
+			case satisfiable:
 				return s.litMap.Variables(s.g), nil
+			case unknown:
+				return nil, Incomplete{Variables: selection, Err: ctx.Err()}
 			}
 		}
 		// Something is wrong if we can't find a model anymore
 		// after optimizing for cardinality.
 		return nil, fmt.Errorf("unexpected internal error")
 	case unsatisfiable:
-		return nil, NotSatisfiable(s.litMap.Conflicts(s.g))
+		conflicts := s.litMap.ConflictLits(s.g)
+		s.g.Untest()
+		return nil, s.notSatisfiable(ctx, conflicts)
+	}
+
+	s.g.Untest()
+	var progress []Variable
+	for _, m := range assumptions {
+		progress = append(progress, s.litMap.VariableOf(m))
+	}
+	return nil, Incomplete{Variables: progress, Err: ctx.Err()}
+}
+
+// events returns an emitter of Events to the configured Tracer.
+func (s *solver) events() emitter {
+	return newEmitter(s.tracer)
+}
+
+// traceResult emits an EventResult describing the values returned by
+// Solve.
+func (s *solver) traceResult(result []Variable, err error) {
+	e := s.events()
+	if !e.enabled() {
+		return
+	}
+	event := Event{Kind: EventResult, Outcome: outcomeString(unknown)}
+	var conflicts NotSatisfiable
+	switch {
+	case err == nil:
+		event.Outcome = outcomeString(satisfiable)
+		event.Variables = identifiersOf(result)
+	case errors.As(err, &conflicts):
+		event.Outcome = outcomeString(unsatisfiable)
+		event.Conflicts = NotSatisfiable(conflicts).Descriptions()
 	}
+	e.emit(event)
+}
+
+// finishStats completes the statistics of the current call to Solve
+// and publishes them, if requested.
+func (s *solver) finishStats() {
+	if s.statsOut == nil {
+		return
+	}
+	s.stats.Variables = len(s.litMap.inorder)
+	s.stats.Gates = s.litMap.c.Len() - 1 - len(s.litMap.c.InPos(nil))
+	*s.statsOut = s.stats
+}
+
+// anchors returns the literals of all Variables with an anchor
+// constraint, or assumed to be selected by SolveUnder, in input
+// order.
+func (s *solver) anchors() []z.Lit {
+	ids := s.litMap.AnchorIdentifiers()
+	ms := make([]z.Lit, len(ids))
+	for i, id := range ids {
+		ms[i] = s.litMap.LitOf(id)
+	}
+	if len(s.hypotheses) == 0 {
+		return ms
+	}
+
+	anchored := make(map[z.Lit]struct{}, len(ms)+len(s.hypotheses))
+	for _, m := range append(ms, s.hypotheses...) {
+		anchored[m] = struct{}{}
+	}
+	ms = ms[:0]
+	for _, m := range s.litMap.Lits(nil) {
+		if _, ok := anchored[m]; ok {
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+// assumeConstraints assumes that all constraints hold, that the
+// solution is optimal with respect to soft constraints, and that any
+// temporarily added clauses are active.
+func (s *solver) assumeConstraints() {
+	s.litMap.AssumeConstraints(s.g)
+	s.g.Assume(s.bounds...)
+	s.g.Assume(s.guards...)
+}
 
-	return nil, ErrIncomplete
+// minimizeCost finds the minimum total cost of SoftConstraint
+// violations among all solutions and sets a bound limiting the cost
+// of solutions to that minimum. If a prior selection was provided, it
+// then bounds the number of its Variables that are not selected, and
+// finally the number of other Variables that are, to their minimums.
+func (s *solver) minimizeCost(ctx context.Context, anchors []z.Lit) error {
+	s.bounds = s.bounds[:0]
+	ms, ws := s.litMap.Penalties()
+	if len(ms) > 0 {
+		bound, err := s.minimize(ctx, anchors, ms, ws)
+		if err != nil {
+			return err
+		}
+		s.bounds = append(s.bounds, bound)
+	}
+	if s.prior == nil {
+		return nil
+	}
+	removals, additions := s.changes()
+	for _, ms := range [][]z.Lit{removals, additions} {
+		if len(ms) == 0 {
+			continue
+		}
+		bound, err := s.minimize(ctx, anchors, ms, nil)
+		if err != nil {
+			return err
+		}
+		s.bounds = append(s.bounds, bound)
+	}
+	return nil
+}
+
+// changes returns the literals that are true in a solution for each
+// Variable of the prior selection that is not selected, and for each
+// other Variable that is.
+func (s *solver) changes() (removals, additions []z.Lit) {
+	for _, v := range s.litMap.inorder {
+		m := s.litMap.LitOf(v.Identifier())
+		if _, ok := s.prior[v.Identifier()]; ok {
+			removals = append(removals, m.Not())
+		} else {
+			additions = append(additions, m)
+		}
+	}
+	return removals, additions
+}
+
+// minimize finds the smallest total weight of the literals in ms
+// that are true in any solution, where ws gives the weight of each
+// or is nil if every weight is one, and returns a literal that, when
+// assumed, bounds that total to the minimum. If there are no
+// solutions at all, the returned literal is trivially true.
+func (s *solver) minimize(ctx context.Context, anchors []z.Lit, ms []z.Lit, ws []int) (z.Lit, error) {
+	unit := true
+	for _, w := range ws {
+		unit = unit && w == 1
+	}
+	if unit {
+		ws = nil
+	}
+	weight := func(i int) int {
+		if ws == nil {
+			return 1
+		}
+		return ws[i]
+	}
+	// value returns the total weight of the literals in ms that
+	// are not false in the current model. Those with unassigned
+	// variables do not appear in any clause, so they count as
+	// true.
+	value := func() int {
+		n := 0
+		for i, m := range ms {
+			if !s.g.Value(m.Not()) {
+				n += weight(i)
+			}
+		}
+		return n
+	}
+
+	// Any solution bounds the minimum from above, so that the
+	// search only requests bounds that are no larger, each of
+	// which is encoded when first requested.
+	s.g.Assume(anchors...)
+	s.assumeConstraints()
+	var hi int
+	switch solveContext(ctx, s.g) {
+	case satisfiable:
+		hi = value()
+	case unsatisfiable:
+		return s.litMap.c.T, nil
+	default:
+		return z.LitNull, Incomplete{Err: ctx.Err()}
+	}
+	var bound func(k int) z.Lit
+	if ws == nil {
+		bound = s.litMap.CardinalityConstrainer(s.g, ms).Leq
+	} else {
+		bound = func(k int) z.Lit {
+			return s.litMap.bound(s.g, ms, ws, k)
+		}
+	}
+
+	// Binary search for the lowest satisfiable bound.
+	lo := 0
+	for lo < hi {
+		if err := ctx.Err(); err != nil {
+			return z.LitNull, Incomplete{Err: err}
+		}
+		w := (lo + hi) / 2
+		m := bound(w)
+		s.g.Assume(anchors...)
+		s.assumeConstraints()
+		s.g.Assume(m)
+		s.stats.MinimizationIterations++
+		outcome := solveContext(ctx, s.g)
+		s.events().emit(Event{Kind: EventMinimizationStep, Bound: &w, Outcome: outcomeString(outcome)})
+		switch outcome {
+		case satisfiable:
+			// Tighten the bound to the value of the
+			// objective in the model.
+			if n := value(); n < w {
+				hi = n
+			} else {
+				hi = w
+			}
+		case unsatisfiable:
+			lo = w + 1
+		default:
+			return z.LitNull, Incomplete{Err: ctx.Err()}
+		}
+	}
+	return bound(hi), nil
+}
+
+// SolveAll returns up to limit distinct solutions, or every solution
+// if limit is not positive. The first solution is the one returned by
+// Solve, and each subsequent solution is the one Solve would return
+// if all previously returned solutions were ruled out. If the
+// provided Context times out or is cancelled, the solutions found so
+// far are returned along with an error.
+func (s *solver) SolveAll(ctx context.Context, limit int) ([][]Variable, error) {
+	// Each solution is ruled out before solving again, which
+	// solving components separately would disregard.
+	act := s.litMap.c.Lit()
+	s.guards = append(s.guards, act)
+	decompose := s.decompose
+	defer func() {
+		s.guards = s.guards[:len(s.guards)-1]
+		s.decompose = decompose
+	}()
+	s.decompose = false
+
+	var result [][]Variable
+	for limit <= 0 || len(result) < limit {
+		selection, err := s.Solve(ctx)
+		if errors.As(err, &NotSatisfiable{}) && len(result) > 0 {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, selection)
+		s.block(act, selection)
+	}
+	return result, nil
+}
+
+// SolveAllOptimal returns up to limit distinct optimal solutions, or
+// every optimal solution if limit is not positive. Optimal solutions
+// are those with the minimum total cost of violated SoftConstraints
+// and, if a prior selection was provided, among those, the minimum
+// number of prior Variables left out, and then the minimum number of
+// other Variables selected. Among the remaining solutions, only those
+// with the minimum number of selected Variables are optimal. Unlike
+// Solve, preferences expressed through Dependency order are not
+// considered, and solutions are returned in no particular order.
+func (s *solver) SolveAllOptimal(ctx context.Context, limit int) (result [][]Variable, err error) {
+	defer func() {
+		if derr := s.litMap.Error(); derr != nil {
+			result = nil
+			err = derr
+		}
+	}()
+
+	s.litMap.AddConstraints(s.g)
+	anchors := s.anchors()
+	if err := s.minimizeCost(ctx, anchors); err != nil {
+		return nil, err
+	}
+	size, err := s.minimize(ctx, anchors, s.litMap.Lits(nil), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	act := s.litMap.c.Lit()
+	for limit <= 0 || len(result) < limit {
+		if err := ctx.Err(); err != nil {
+			return result, Incomplete{Err: err}
+		}
+		s.g.Assume(anchors...)
+		s.assumeConstraints()
+		s.g.Assume(size, act)
+		switch solveContext(ctx, s.g) {
+		case satisfiable:
+			selection := s.litMap.Variables(s.g)
+			result = append(result, selection)
+			s.block(act, selection)
+		case unsatisfiable:
+			if len(result) == 0 {
+				return nil, s.notSatisfiable(ctx, s.litMap.ConflictLits(s.g))
+			}
+			return result, nil
+		default:
+			return result, Incomplete{Err: ctx.Err()}
+		}
+	}
+	return result, nil
+}
+
+// notSatisfiable returns a NotSatisfiable error composed of the
+// constraints encoded by the provided literals, which must not be
+// satisfiable together. If minimal conflicts were requested, the
+// constraints are first reduced to a minimal unsatisfiable subset.
+func (s *solver) notSatisfiable(ctx context.Context, conflicts []z.Lit) error {
+	if s.minimalConflicts {
+		var err error
+		background := append(append([]z.Lit(nil), s.bounds...), s.guards...)
+		if conflicts, err = s.minimizeConflicts(ctx, conflicts, background...); err != nil {
+			return err
+		}
+	}
+	err := NotSatisfiable(s.litMap.ConstraintsOf(conflicts))
+	if s.suggestions <= 0 {
+		return err
+	}
+	corrections, cerr := s.correctionSets(ctx, s.suggestions)
+	if cerr != nil && !errors.As(cerr, &NotSatisfiable{}) {
+		return cerr
+	}
+	return Correctable{NotSatisfiable: err, Corrections: corrections}
+}
+
+// minimizeConflicts reduces the provided constraint literals, which
+// must not be satisfiable together, to a minimal subset that is still
+// unsatisfiable: removing any single literal from the result makes it
+// satisfiable. Each literal is tentatively removed in turn and only
+// kept if the remaining literals become satisfiable without it.
+// Whenever a removal is confirmed, the remainder is further reduced
+// to the failed assumptions reported by the solver. The background
+// literals are assumed throughout and never removed.
+func (s *solver) minimizeConflicts(ctx context.Context, conflicts []z.Lit, background ...z.Lit) ([]z.Lit, error) {
+	core := append([]z.Lit(nil), conflicts...)
+	for i := 0; i < len(core); {
+		if err := ctx.Err(); err != nil {
+			return nil, Incomplete{Err: err}
+		}
+		s.litMap.assumeAbsent(s.g)
+		s.g.Assume(background...)
+		s.g.Assume(core[:i]...)
+		s.g.Assume(core[i+1:]...)
+		switch solveContext(ctx, s.g) {
+		case satisfiable:
+			i++
+		case unsatisfiable:
+			failed := make(map[z.Lit]struct{})
+			for _, m := range s.g.Why(nil) {
+				failed[m] = struct{}{}
+			}
+			// Literals preceding i are necessary, so
+			// they are always among the failed
+			// assumptions.
+			reduced := core[:0]
+			for j, m := range core {
+				if _, ok := failed[m]; ok && j != i {
+					reduced = append(reduced, m)
+				}
+			}
+			core = reduced
+		default:
+			return nil, Incomplete{Err: ctx.Err()}
+		}
+	}
+	return core, nil
+}
+
+// block adds a clause, active only while act is assumed, that rules
+// out the given selection.
+func (s *solver) block(act z.Lit, selection []Variable) {
+	selected := make(map[Identifier]struct{}, len(selection))
+	for _, v := range selection {
+		selected[v.Identifier()] = struct{}{}
+	}
+	s.g.Add(act.Not())
+	for _, v := range s.litMap.inorder {
+		m := s.litMap.LitOf(v.Identifier())
+		if _, ok := selected[v.Identifier()]; ok {
+			m = m.Not()
+		}
+		s.g.Add(m)
+	}
+	s.g.Add(z.LitNull)
+}
+
+const (
+	minSolvePoll = 50 * time.Microsecond
+	maxSolvePoll = 10 * time.Millisecond
+)
+
+// solveContext calls Solve on g, stopping early and returning unknown
+// if ctx is cancelled or times out before a result is available. If g
+// does not implement inter.GoSolvable, it can only stop early if ctx
+// is done before solving begins.
+func solveContext(ctx context.Context, g Backend) int {
+	if ctx.Err() != nil {
+		return unknown
+	}
+	goSolvable, ok := g.(inter.GoSolvable)
+	if ctx.Done() == nil || !ok {
+		return g.Solve()
+	}
+	gs := goSolvable.GoSolve()
+	poll := minSolvePoll
+	timer := time.NewTimer(poll)
+	defer timer.Stop()
+	for {
+		if result, done := gs.Test(); done {
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			return gs.Stop()
+		case <-timer.C:
+		}
+		if poll < maxSolvePoll {
+			poll *= 2
+		}
+		timer.Reset(poll)
+	}
 }
 
 func NewSolver(options ...Option) (Solver, error) {
-	s := solver{g: gini.New()}
+	return newSolver(options...)
+}
+
+func newSolver(options ...Option) (*solver, error) {
+	var s solver
 	for _, option := range append(options, defaults...) {
 		if err := option(&s); err != nil {
 			return nil, err
@@ -132,9 +681,8 @@ type Option func(s *solver) error
 
 func WithInput(input []Variable) Option {
 	return func(s *solver) error {
-		var err error
-		s.litMap, err = newLitMapping(input)
-		return err
+		s.input = input
+		return nil
 	}
 }
 
@@ -145,15 +693,203 @@ func WithTracer(t Tracer) Option {
 	}
 }
 
+// WithMinimalConflicts configures the solver to reduce the
+// constraints reported in NotSatisfiable errors to a minimal
+// unsatisfiable subset. Constraints that are encoded identically,
+// such as a Conflict declared from both sides, are all reported, and
+// are kept or removed together: the problem would become satisfiable
+// if any reported constraint were removed along with the others
+// sharing its encoding. Without this option, reported constraints
+// are sufficient, but not necessarily minimal, to make a solution
+// impossible.
+func WithMinimalConflicts() Option {
+	return func(s *solver) error {
+		s.minimalConflicts = true
+		return nil
+	}
+}
+
+// WithNonRelaxable configures the solver to never suggest relaxing
+// applied constraints for which the provided function returns true,
+// for example those that anchor the Variables a user has explicitly
+// requested.
+func WithNonRelaxable(f func(AppliedConstraint) bool) Option {
+	return func(s *solver) error {
+		s.nonRelaxable = f
+		return nil
+	}
+}
+
+// WithSuggestions configures the solver to return a Correctable error,
+// including up to limit minimal correction sets, whenever the problem
+// is not satisfiable. No suggestions are made if limit is not
+// positive.
+func WithSuggestions(limit int) Option {
+	return func(s *solver) error {
+		s.suggestions = limit
+		return nil
+	}
+}
+
+// WithStats configures the solver to record statistics about each
+// call to Solve in the provided Stats, replacing those of the previous
+// call.
+func WithStats(stats *Stats) Option {
+	return func(s *solver) error {
+		s.statsOut = stats
+		return nil
+	}
+}
+
+// WithBackend configures the solver to use the provided Backend
+// instead of gini. The Backend must not have been used before.
+func WithBackend(b Backend) Option {
+	return func(s *solver) error {
+		s.g = b
+		return nil
+	}
+}
+
+// WithDanglingReferences configures the solver to accept input in
+// which constraints refer to Identifiers that do not identify any
+// Variable. Such Identifiers are treated as identifying Variables
+// that can never be selected, so that, for example, a Dependency on
+// nothing else is unsatisfiable and is reported as such by Solve.
+// Without this option, NewSolver returns a DanglingReference error.
+func WithDanglingReferences() Option {
+	return func(s *solver) error {
+		s.placeholders = true
+		return nil
+	}
+}
+
+// WithPruning configures the solver to encode only those Variables
+// that can be reached from the anchors of the input, following the
+// Identifiers referenced by the constraints of each reached Variable,
+// including through Conflicts and cardinality constraints. Variables
+// with constraints that could rule out a solution even when they are
+// not selected, such as AtLeast, with SoftConstraints, or that are
+// part of the prior selection, are reached as well. All other
+// Variables are never selected, which can greatly reduce the cost of
+// solving when a large input is mostly irrelevant to its anchors.
+// Explain reports that such Variables were pruned, and SolveUnder
+// rejects assumptions about them with a PrunedVariable error.
+func WithPruning() Option {
+	return func(s *solver) error {
+		s.prune = true
+		return nil
+	}
+}
+
+// WithDecomposition configures the solver to split its input into
+// components, such that no constraint refers to Variables in more
+// than one component, and to solve each component with its own
+// instance of gini, using up to GOMAXPROCS goroutines. Components
+// with no anchors, SoftConstraints, constraints that apply to
+// unselected Variables or Variables of the prior selection are not
+// solved at all, since none of their Variables would be selected.
+// The selections of all components are merged, as are the conflicts
+// of all unsatisfiable components, so the result is the same as that
+// of solving the input as a whole. Tracers do not receive events
+// about the search for each component, and WithBackend does not
+// apply to components. Problems consisting of a single component are
+// solved as usual, as are all problems solved by SolveAll,
+// SolveAllOptimal, SolveUnder or a Session.
+func WithDecomposition() Option {
+	return func(s *solver) error {
+		s.decompose = true
+		return nil
+	}
+}
+
+// WithSearchStrategy configures the order in which the solver makes
+// choices between the candidates of each Dependency while searching
+// for a solution. The default is BreadthFirst.
+func WithSearchStrategy(strategy SearchStrategy) Option {
+	return func(s *solver) error {
+		s.strategy = strategy
+		return nil
+	}
+}
+
+// WithPriorSelection configures the solver to prefer solutions that
+// differ as little as possible from a previous selection, such as the
+// set of Variables that is currently installed. Among the solutions
+// of minimal cost with respect to SoftConstraints, only those that
+// retain as many of the prior Variables as possible are considered
+// and, among those, only those that select as few other Variables as
+// possible. Preferences expressed through Dependency order are
+// respected within those limits. Prior Variables are matched by
+// Identifier, and any that are not part of the input are ignored.
+func WithPriorSelection(prior []Variable) Option {
+	return func(s *solver) error {
+		s.prior = make(map[Identifier]struct{}, len(prior))
+		for _, v := range prior {
+			s.prior[v.Identifier()] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithCardinalityEncoding configures how the solver encodes bounds on
+// the number of selected Variables among a group, including those
+// imposed by AtMost, AtLeast and Exactly, and those used to minimize
+// the cost and size of solutions. The default is AutomaticEncoding.
+func WithCardinalityEncoding(encoding CardinalityEncoding) Option {
+	return func(s *solver) error {
+		s.encoding = encoding
+		return nil
+	}
+}
+
 var defaults = []Option{
 	func(s *solver) error {
-		if s.litMap == nil {
-			var err error
-			s.litMap, err = newLitMapping(nil)
-			return err
+		if s.g == nil {
+			s.g = gini.New()
+		}
+		if s.statsOut != nil {
+			s.g = newCountingBackend(s.g, &s.stats)
 		}
 		return nil
 	},
+	func(s *solver) error {
+		if s.litMap != nil {
+			return nil
+		}
+		if s.prune {
+			input := s.input
+			s.input = prune(input, s.prior)
+			s.pruned = make(map[Identifier]struct{}, len(input)-len(s.input))
+			for _, v := range input {
+				s.pruned[v.Identifier()] = struct{}{}
+			}
+			for _, v := range s.input {
+				delete(s.pruned, v.Identifier())
+			}
+		}
+		if !s.placeholders {
+			// Report dangling references before mapping
+			// the input, rather than as an internal
+			// failure after solving.
+			for _, err := range Validate(s.input) {
+				if _, ok := err.(DanglingReference); ok {
+					return err
+				}
+			}
+		}
+		start := time.Now()
+		defer func() {
+			s.mapping = time.Since(start)
+		}()
+		var err error
+		s.litMap, err = newLitMapping(nil)
+		if err != nil {
+			return err
+		}
+		s.litMap.placeholders = s.placeholders
+		s.litMap.encoding = s.encoding
+		return s.litMap.add(s.input)
+	},
 	func(s *solver) error {
 		if s.tracer == nil {
 			s.tracer = DefaultTracer{}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +38,14 @@ func variable(id Identifier, constraints ...Constraint) Variable {
 	}
 }
 
+func identifiers(vs []Variable) []Identifier {
+	var ids []Identifier
+	for _, v := range vs {
+		ids = append(ids, v.Identifier())
+	}
+	return ids
+}
+
 func TestNotSatisfiableError(t *testing.T) {
 	type tc struct {
 		Name   string
@@ -295,6 +305,181 @@ func TestSolve(t *testing.T) {
 			},
 			Installed: []Identifier{"a", "x1", "y1"},
 		},
+		{
+			Name: "at least constraint selects variables",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y")),
+				variable("b", Mandatory(), Dependency("z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "b", "x", "y", "z"},
+		},
+		{
+			Name: "at least constraint prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y")),
+				variable("x", Prohibited()),
+				variable("y"),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), AtLeast(2, "x", "y")),
+					Constraint: AtLeast(2, "x", "y"),
+				},
+				{
+					Variable:   variable("x", Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+		{
+			Name: "exactly constraint selects a single provider",
+			Variables: []Variable{
+				variable("a", Mandatory(), Exactly(1, "x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "exactly constraint prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Exactly(1, "x", "y")),
+				variable("x", Mandatory()),
+				variable("y", Mandatory()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Exactly(1, "x", "y")),
+					Constraint: Exactly(1, "x", "y"),
+				},
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("y", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+		},
+		{
+			Name: "between constraint bounds both sides",
+			Variables: []Variable{
+				variable("a", Mandatory(), Between(1, 2, "x", "y", "z")),
+				variable("b", Mandatory(), Dependency("x"), Dependency("y"), Dependency("z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Between(1, 2, "x", "y", "z")),
+					Constraint: Between(1, 2, "x", "y", "z"),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Dependency("x"), Dependency("y"), Dependency("z")),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Dependency("x"), Dependency("y"), Dependency("z")),
+					Constraint: Dependency("x"),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Dependency("x"), Dependency("y"), Dependency("z")),
+					Constraint: Dependency("y"),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Dependency("x"), Dependency("y"), Dependency("z")),
+					Constraint: Dependency("z"),
+				},
+			},
+		},
+		{
+			Name: "penalty overrides dependency preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Penalty(1)),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "preferred variable is installed",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b", Prefer(1)),
+			},
+			Installed: []Identifier{"a", "b"},
+		},
+		{
+			Name: "heavier preference wins",
+			Variables: []Variable{
+				variable("a", Prefer(2)),
+				variable("b", Prefer(1), Conflict("a")),
+			},
+			Installed: []Identifier{"a"},
+		},
+		{
+			Name: "lighter preference loses",
+			Variables: []Variable{
+				variable("a", Prefer(1)),
+				variable("b", Prefer(2), Conflict("a")),
+			},
+			Installed: []Identifier{"b"},
+		},
+		{
+			Name: "lighter preferences win together",
+			Variables: []Variable{
+				variable("a", Prefer(3)),
+				variable("b", Prefer(2), Conflict("a")),
+				variable("c", Prefer(2), Conflict("a")),
+			},
+			Installed: []Identifier{"b", "c"},
+		},
+		{
+			Name: "large weights",
+			Variables: []Variable{
+				variable("a", Prefer(200000)),
+				variable("b", Prefer(1), Conflict("a")),
+			},
+			Installed: []Identifier{"a"},
+		},
+		{
+			Name: "total cost is minimized",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("y", "x")),
+				variable("x", Penalty(3)),
+				variable("y", Penalty(2), Dependency("z")),
+				variable("z", Penalty(2)),
+			},
+			Installed: []Identifier{"a", "x"},
+		},
+		{
+			Name: "soft constraint yields to hard constraint",
+			Variables: []Variable{
+				variable("a", Prefer(5), Prohibited()),
+			},
+		},
+		{
+			Name: "soft constraints are not reported as conflicts",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited(), Prefer(1)),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Prohibited(), Prefer(1)),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Prohibited(), Prefer(1)),
+					Constraint: Prohibited(),
+				},
+			},
+		},
 	} {
 		t.Run(tt.Name, func(t *testing.T) {
 			assert := assert.New(t)
@@ -356,6 +541,68 @@ func TestSolve(t *testing.T) {
 	}
 }
 
+func TestSolveSharedConstraintLiterals(t *testing.T) {
+	symmetric := []Variable{
+		variable("a", Mandatory(), Conflict("b")),
+		variable("b", Mandatory(), Conflict("a")),
+	}
+	duplicate := []Variable{
+		variable("a", Mandatory(), Mandatory(), Prohibited()),
+	}
+	empty := []Variable{
+		variable("a", Mandatory(), Prohibited(), Dependency()),
+	}
+
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Conflicts NotSatisfiable
+	}{
+		{
+			Name:      "symmetric conflicts",
+			Variables: symmetric,
+			Conflicts: NotSatisfiable{
+				{Variable: symmetric[0], Constraint: Mandatory()},
+				{Variable: symmetric[0], Constraint: Conflict("b")},
+				{Variable: symmetric[1], Constraint: Mandatory()},
+				{Variable: symmetric[1], Constraint: Conflict("a")},
+			},
+		},
+		{
+			Name:      "duplicate constraints",
+			Variables: duplicate,
+			Conflicts: NotSatisfiable{
+				{Variable: duplicate[0], Constraint: Mandatory()},
+				{Variable: duplicate[0], Constraint: Mandatory()},
+				{Variable: duplicate[0], Constraint: Prohibited()},
+			},
+		},
+		{
+			Name:      "distinct constraints with the same encoding",
+			Variables: empty,
+			Conflicts: NotSatisfiable{
+				{Variable: empty[0], Constraint: Mandatory()},
+				{Variable: empty[0], Constraint: Prohibited()},
+				{Variable: empty[0], Constraint: Dependency()},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			for _, options := range [][]Option{nil, {WithMinimalConflicts()}} {
+				s, err := NewSolver(append(options, WithInput(tt.Variables))...)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = s.Solve(context.TODO())
+				var conflicts NotSatisfiable
+				if assert.True(t, errors.As(err, &conflicts)) {
+					assert.ElementsMatch(t, tt.Conflicts, conflicts)
+				}
+			}
+		})
+	}
+}
+
 func TestDuplicateIdentifier(t *testing.T) {
 	_, err := NewSolver(WithInput([]Variable{
 		variable("a"),
@@ -363,3 +610,436 @@ func TestDuplicateIdentifier(t *testing.T) {
 	}))
 	assert.Equal(t, DuplicateIdentifier("a"), err)
 }
+
+type cancellingTracer struct {
+	cancel context.CancelFunc
+}
+
+func (t cancellingTracer) Trace(_ SearchPosition) {
+	t.cancel()
+}
+
+func TestSolveCancellation(t *testing.T) {
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("a1", "a2")),
+		variable("a1", Conflict("c1"), Conflict("c2")),
+		variable("a2", Conflict("c1")),
+		variable("c", Mandatory(), Dependency("c1", "c2")),
+		variable("c1"),
+		variable("c2"),
+	}
+
+	t.Run("cancelled before solving", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		s, err := NewSolver(WithInput(input))
+		assert.NoError(err)
+
+		installed, err := s.Solve(ctx)
+		assert.Nil(installed)
+		assert.ErrorIs(err, ErrIncomplete)
+		assert.ErrorIs(err, context.Canceled)
+
+		var incomplete Incomplete
+		if assert.ErrorAs(err, &incomplete) {
+			assert.Empty(incomplete.Variables)
+		}
+	})
+
+	t.Run("cancelled during search", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The tracer fires at the first unsatisfiable position,
+		// which occurs only after several guesses have been made.
+		s, err := NewSolver(WithInput(input), WithTracer(cancellingTracer{cancel: cancel}))
+		assert.NoError(err)
+
+		installed, err := s.Solve(ctx)
+		assert.Nil(installed)
+		assert.ErrorIs(err, ErrIncomplete)
+		assert.ErrorIs(err, context.Canceled)
+
+		var incomplete Incomplete
+		if assert.ErrorAs(err, &incomplete) {
+			var ids []Identifier
+			for _, v := range incomplete.Variables {
+				ids = append(ids, v.Identifier())
+			}
+			assert.Equal([]Identifier{"a", "c"}, ids)
+		}
+	})
+
+	t.Run("not cancelled", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s, err := NewSolver(WithInput(input))
+		assert.NoError(err)
+
+		installed, err := s.Solve(ctx)
+		assert.NoError(err)
+		var ids []Identifier
+		for _, v := range installed {
+			ids = append(ids, v.Identifier())
+		}
+		assert.Equal([]Identifier{"a", "a2", "c", "c2"}, ids)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		s, err := NewSolver(WithInput(input))
+		assert.NoError(err)
+
+		_, err = s.Solve(ctx)
+		assert.ErrorIs(err, ErrIncomplete)
+		assert.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
+
+func TestSolveAll(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Limit     int
+		Optimal   bool
+		Solutions [][]Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "every solution in preference order",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "x", "y"},
+				{"a", "y"},
+			},
+		},
+		{
+			Name: "limit is respected",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Limit: 2,
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "x", "y"},
+			},
+		},
+		{
+			Name: "constraints hold in every solution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Conflict("y")),
+				variable("y"),
+			},
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "y"},
+			},
+		},
+		{
+			Name: "unsatisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+		{
+			Name: "only optimal solutions",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Optimal: true,
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "y"},
+			},
+		},
+		{
+			Name: "optimal solutions account for cost",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y", "z")),
+				variable("x", Penalty(1)),
+				variable("y"),
+				variable("z"),
+			},
+			Optimal: true,
+			Solutions: [][]Identifier{
+				{"a", "y"},
+				{"a", "z"},
+			},
+		},
+		{
+			Name: "optimal solutions with limit",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Optimal: true,
+			Limit:   1,
+		},
+		{
+			Name: "no optimal solution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Optimal: true,
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := NewSolver(WithInput(tt.Variables))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			var solutions [][]Variable
+			if tt.Optimal {
+				solutions, err = s.SolveAllOptimal(context.TODO(), tt.Limit)
+			} else {
+				solutions, err = s.SolveAll(context.TODO(), tt.Limit)
+			}
+
+			var ids [][]Identifier
+			for _, solution := range solutions {
+				var each []Identifier
+				for _, variable := range solution {
+					each = append(each, variable.Identifier())
+				}
+				ids = append(ids, each)
+			}
+
+			switch {
+			case tt.Optimal && tt.Limit > 0:
+				assert.Len(ids, tt.Limit)
+			case tt.Optimal:
+				assert.ElementsMatch(tt.Solutions, ids)
+			default:
+				assert.Equal(tt.Solutions, ids)
+			}
+
+			if tt.Error == nil {
+				assert.NoError(err)
+				return
+			}
+			assert.ElementsMatch(tt.Error, err)
+		})
+	}
+}
+
+func TestSolveAfterSolveAll(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSolver(WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x"),
+		variable("y"),
+	}))
+	assert.NoError(err)
+
+	solutions, err := s.SolveAll(context.TODO(), 0)
+	assert.NoError(err)
+	assert.Len(solutions, 3)
+
+	// Solutions ruled out during enumeration are available again.
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	var ids []Identifier
+	for _, variable := range installed {
+		ids = append(ids, variable.Identifier())
+	}
+	assert.Equal([]Identifier{"a", "x"}, ids)
+}
+
+// randomVariables returns n Variables with randomly chosen
+// constraints, each referring only to Variables among the n.
+func randomVariables(r *rand.Rand, n int) []Variable {
+	return randomPrefixedVariables(r, "v", n)
+}
+
+// randomPrefixedVariables is like randomVariables, but the
+// Identifiers of the returned Variables begin with prefix.
+func randomPrefixedVariables(r *rand.Rand, prefix string, n int) []Variable {
+	ids := make([]Identifier, n)
+	for i := range ids {
+		ids[i] = Identifier(fmt.Sprintf("%s%d", prefix, i))
+	}
+	some := func() []Identifier {
+		var result []Identifier
+		for _, id := range ids {
+			if r.Intn(3) == 0 {
+				result = append(result, id)
+			}
+		}
+		return result
+	}
+	vars := make([]Variable, n)
+	for i, id := range ids {
+		var cs []Constraint
+		if r.Intn(3) == 0 {
+			cs = append(cs, Mandatory())
+		}
+		if r.Intn(8) == 0 {
+			cs = append(cs, Prohibited())
+		}
+		if r.Intn(2) == 0 {
+			cs = append(cs, Dependency(some()...))
+		}
+		if r.Intn(3) == 0 {
+			cs = append(cs, Conflict(ids[r.Intn(n)]))
+		}
+		if r.Intn(4) == 0 {
+			cs = append(cs, AtMost(1, some()...))
+		}
+		vars[i] = variable(id, cs...)
+	}
+	return vars
+}
+
+// restrict returns copies of the provided Variables, retaining only
+// the constraints for which keep returns true.
+func restrict(vars []Variable, keep func(AppliedConstraint) bool) []Variable {
+	result := make([]Variable, len(vars))
+	for i, v := range vars {
+		var cs []Constraint
+		for _, c := range v.Constraints() {
+			if keep(AppliedConstraint{Variable: v, Constraint: c}) {
+				cs = append(cs, c)
+			}
+		}
+		result[i] = variable(v.Identifier(), cs...)
+	}
+	return result
+}
+
+func TestSolveMinimalConflicts(t *testing.T) {
+	solve := func(vars []Variable, options ...Option) error {
+		s, err := NewSolver(append([]Option{WithInput(vars)}, options...)...)
+		if err != nil {
+			t.Fatalf("failed to initialize solver: %s", err)
+		}
+		_, err = s.Solve(context.TODO())
+		return err
+	}
+	in := func(conflicts NotSatisfiable, a AppliedConstraint) bool {
+		for _, each := range conflicts {
+			if reflect.DeepEqual(each, a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var checked int
+	for i := 0; checked < 500; i++ {
+		vars := randomVariables(r, 2+r.Intn(10))
+		var conflicts NotSatisfiable
+		if !errors.As(solve(vars, WithMinimalConflicts()), &conflicts) {
+			continue
+		}
+		checked++
+		lm, err := newLitMapping(vars)
+		if err != nil {
+			t.Fatalf("failed to encode %#v: %s", vars, err)
+		}
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			// The reported constraints are unsatisfiable on
+			// their own...
+			var err NotSatisfiable
+			assert.True(errors.As(solve(restrict(vars, func(a AppliedConstraint) bool {
+				return in(conflicts, a)
+			})), &err), "%#v", vars)
+
+			// ...but removing any one of them, along with
+			// any others encoded by the same literal, makes
+			// the remainder satisfiable.
+			for _, removed := range conflicts {
+				var shared NotSatisfiable
+				for _, as := range lm.constraints {
+					if in(as, removed) {
+						shared = as
+					}
+				}
+				assert.NoError(solve(restrict(vars, func(a AppliedConstraint) bool {
+					return in(conflicts, a) && !in(shared, a)
+				})), "%#v without %s", vars, removed)
+			}
+		})
+	}
+}
+
+func TestSolveMinimalConflictsExcludesIrrelevant(t *testing.T) {
+	assert := assert.New(t)
+
+	// b and d cannot both be selected, and neither can b and c.
+	// Neither c's conflict nor its cardinality constraint is
+	// needed to explain the failure.
+	input := []Variable{
+		variable("a"),
+		variable("b", Mandatory(), Dependency("c", "d")),
+		variable("c", Conflict("b"), AtMost(1, "a", "d")),
+		variable("d", Mandatory(), AtMost(1, "b", "c", "d")),
+	}
+
+	s, err := NewSolver(WithMinimalConflicts(), WithInput(input))
+	assert.NoError(err)
+
+	_, err = s.Solve(context.TODO())
+	var conflicts NotSatisfiable
+	assert.True(errors.As(err, &conflicts))
+	// Either d's mandatory constraint or b's dependency completes
+	// the conflict.
+	assert.Len(conflicts, 3)
+	assert.Subset(conflicts, NotSatisfiable{
+		{Variable: input[1], Constraint: Mandatory()},
+		{Variable: input[3], Constraint: AtMost(1, "b", "c", "d")},
+	})
+	assert.NotContains(conflicts, AppliedConstraint{Variable: input[2], Constraint: Conflict("b")})
+	assert.NotContains(conflicts, AppliedConstraint{Variable: input[2], Constraint: AtMost(1, "a", "d")})
+}
@@ -0,0 +1,63 @@
+package sat
+
+import (
+	"time"
+)
+
+// Stats describes the work done by a single call to Solve.
+type Stats struct {
+	// Variables is the number of input Variables.
+	Variables int
+	// Gates is the number of gates in the circuit produced by the
+	// Tseitin transformation of all constraints, including those
+	// used to bound cardinality.
+	Gates int
+	// Clauses is the number of CNF clauses added to the underlying
+	// SAT solver over its lifetime, including those added by
+	// earlier calls to Solve.
+	Clauses int
+	// Anchors is the number of Variables with an anchor
+	// constraint.
+	Anchors int
+	// Guesses is the number of Variables tentatively selected
+	// during search.
+	Guesses int
+	// Backtracks is the number of guesses withdrawn because they
+	// led to a conflict.
+	Backtracks int
+	// Tests is the number of calls to Test on the underlying SAT
+	// solver.
+	Tests int
+	// Solves is the number of calls to Solve on the underlying SAT
+	// solver, whether synchronous or not.
+	Solves int
+	// MinimizationIterations is the number of bounds attempted
+	// while minimizing the cost and size of the solution.
+	MinimizationIterations int
+	// Encode is the time spent encoding constraints as clauses.
+	// The first call to Solve also includes the time spent
+	// mapping the input to literals when the solver was
+	// constructed.
+	Encode time.Duration
+	// Search is the time spent searching for a solution that
+	// respects preferences.
+	Search time.Duration
+	// Minimize is the time spent minimizing the cost and size of
+	// the solution.
+	Minimize time.Duration
+}
+
+// add accumulates the counts and durations of o, other than those
+// describing the input, into s.
+func (s *Stats) add(o Stats) {
+	s.Clauses += o.Clauses
+	s.Anchors += o.Anchors
+	s.Guesses += o.Guesses
+	s.Backtracks += o.Backtracks
+	s.Tests += o.Tests
+	s.Solves += o.Solves
+	s.MinimizationIterations += o.MinimizationIterations
+	s.Encode += o.Encode
+	s.Search += o.Search
+	s.Minimize += o.Minimize
+}
@@ -0,0 +1,64 @@
+package sat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	assert := assert.New(t)
+
+	var stats Stats
+	s, err := NewSolver(WithStats(&stats), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x", Dependency("p", "q")),
+		variable("y"),
+		variable("b", Mandatory(), AtMost(1, "x", "p", "q")),
+		variable("p"),
+		variable("q"),
+	}))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+	assert.NoError(err)
+
+	assert.Equal(6, stats.Variables)
+	assert.Equal(2, stats.Anchors)
+	// a, b and x are guessed before x is withdrawn in favour of y.
+	assert.Equal(4, stats.Guesses)
+	assert.Equal(1, stats.Backtracks)
+	assert.Greater(stats.Gates, 0)
+	assert.Greater(stats.Clauses, 0)
+	assert.Greater(stats.Tests, 0)
+	assert.Greater(stats.Solves, 0)
+	assert.Greater(stats.MinimizationIterations, 0)
+	assert.Greater(stats.Search, time.Duration(0))
+
+	// Statistics are replaced by each call, but the encoding is
+	// only added once.
+	clauses := stats.Clauses
+	_, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal(4, stats.Guesses)
+	assert.Equal(1, stats.Backtracks)
+	assert.Equal(clauses, stats.Clauses)
+}
+
+func TestStatsWithoutGates(t *testing.T) {
+	assert := assert.New(t)
+
+	var stats Stats
+	s, err := NewSolver(WithStats(&stats), WithInput([]Variable{
+		variable("a", Mandatory()),
+		variable("b", Prohibited()),
+	}))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+	assert.NoError(err)
+
+	assert.Equal(2, stats.Variables)
+	assert.Equal(0, stats.Gates)
+	assert.Equal(0, stats.Guesses)
+}
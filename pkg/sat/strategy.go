@@ -0,0 +1,74 @@
+package sat
+
+import (
+	"sort"
+
+	"github.com/go-air/gini/z"
+)
+
+// CandidateScorer returns a score for a candidate that could satisfy
+// a Dependency applied to subject. Candidates with higher scores are
+// tried first.
+type CandidateScorer func(subject Variable, candidate Variable) int
+
+// SearchStrategy determines the order in which the search for a
+// solution makes choices between the candidates of each Dependency,
+// and the order in which it tries those candidates. When the
+// preferences of several Variables compete, those that are decided
+// first win.
+type SearchStrategy struct {
+	depthFirst bool
+	score      CandidateScorer
+}
+
+// BreadthFirst returns the default SearchStrategy, which makes the
+// choices introduced by selecting a Variable only after all choices
+// introduced earlier, and tries candidates in the order they were
+// declared. The preferences of anchors, and of Variables closer to
+// anchors, therefore take precedence.
+func BreadthFirst() SearchStrategy {
+	return SearchStrategy{}
+}
+
+// DepthFirst returns a SearchStrategy that makes the choices
+// introduced by selecting a Variable immediately, before any others,
+// and tries candidates in the order they were declared. All
+// dependencies of an anchor, direct and indirect, are therefore
+// decided before those of the next anchor.
+func DepthFirst() SearchStrategy {
+	return SearchStrategy{depthFirst: true}
+}
+
+// ScoredBy returns a SearchStrategy that makes choices in the same
+// order as BreadthFirst, but tries the candidates of each choice in
+// descending order of the scores assigned by the provided function.
+// Candidates with equal scores are tried in the order they were
+// declared.
+func ScoredBy(score CandidateScorer) SearchStrategy {
+	return SearchStrategy{score: score}
+}
+
+// order returns the candidates of a choice introduced by subject in
+// the order they should be tried. Candidates that are not part of the
+// input are never scored and come last.
+func (s SearchStrategy) order(lits *LitMapping, subject Variable, ms []z.Lit) []z.Lit {
+	if s.score == nil || len(ms) < 2 {
+		return ms
+	}
+	scores := make(map[z.Lit]int, len(ms))
+	for _, m := range ms {
+		if lits.Present(m) {
+			scores[m] = s.score(subject, lits.VariableOf(m))
+		}
+	}
+	result := append([]z.Lit(nil), ms...)
+	sort.SliceStable(result, func(i, j int) bool {
+		si, iok := scores[result[i]]
+		sj, jok := scores[result[j]]
+		if iok != jok {
+			return iok
+		}
+		return si > sj
+	})
+	return result
+}
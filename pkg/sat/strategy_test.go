@@ -0,0 +1,115 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchStrategy(t *testing.T) {
+	// The preferences of a and b for x or y compete, since at
+	// most one of x and y may be selected.
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("p")),
+		variable("b", Mandatory(), Dependency("x", "y")),
+		variable("c", Mandatory(), AtMost(1, "x", "y")),
+		variable("p", Dependency("y", "x")),
+		variable("x"),
+		variable("y"),
+	}
+
+	for _, tt := range []struct {
+		Name      string
+		Strategy  SearchStrategy
+		Installed []Identifier
+	}{
+		{
+			Name:      "breadth first",
+			Strategy:  BreadthFirst(),
+			Installed: []Identifier{"a", "b", "c", "p", "x"},
+		},
+		{
+			Name:      "depth first",
+			Strategy:  DepthFirst(),
+			Installed: []Identifier{"a", "b", "c", "p", "y"},
+		},
+		{
+			Name: "scored",
+			Strategy: ScoredBy(func(subject, candidate Variable) int {
+				if candidate.Identifier() == "y" {
+					return 1
+				}
+				return 0
+			}),
+			Installed: []Identifier{"a", "b", "c", "p", "y"},
+		},
+		{
+			Name: "scored ties keep declared order",
+			Strategy: ScoredBy(func(subject, candidate Variable) int {
+				return 0
+			}),
+			Installed: []Identifier{"a", "b", "c", "p", "x"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			s, err := NewSolver(WithInput(input), WithSearchStrategy(tt.Strategy))
+			if !assert.NoError(t, err) {
+				return
+			}
+			installed, err := s.Solve(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Installed, identifiers(installed))
+		})
+	}
+}
+
+func TestDepthFirstBacktracking(t *testing.T) {
+	// Selecting x first forces a backtrack, after which the
+	// choices introduced by x must be withdrawn from the front of
+	// the deque.
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("b", Mandatory(), Dependency("z")),
+		variable("x", Dependency("p", "q")),
+		variable("y"),
+		variable("z", Conflict("x")),
+		variable("p"),
+		variable("q"),
+	}
+	s, err := NewSolver(WithInput(input), WithSearchStrategy(DepthFirst()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	installed, err := s.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Identifier{"a", "b", "y", "z"}, identifiers(installed))
+}
+
+func TestSearchStrategiesAgree(t *testing.T) {
+	r := rand.New(rand.NewSource(19))
+	reverse := ScoredBy(func(_, candidate Variable) int {
+		return -len(candidate.Constraints())
+	})
+	for i := 0; i < 200; i++ {
+		vars := randomVariables(r, 1+r.Intn(12))
+		var outcomes []bool
+		for _, strategy := range []SearchStrategy{BreadthFirst(), DepthFirst(), reverse} {
+			s, err := NewSolver(WithInput(vars), WithSearchStrategy(strategy))
+			if !assert.NoError(t, err) {
+				return
+			}
+			installed, err := s.Solve(context.Background())
+			if err != nil && !errors.As(err, &NotSatisfiable{}) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil {
+				assert.Empty(t, Verify(vars, installed))
+			}
+			outcomes = append(outcomes, err == nil)
+		}
+		assert.Equal(t, []bool{outcomes[0], outcomes[0], outcomes[0]}, outcomes)
+	}
+}
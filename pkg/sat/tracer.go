@@ -1,8 +1,11 @@
 package sat
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 )
 
 type SearchPosition interface {
@@ -33,3 +36,143 @@ func (t LoggingTracer) Trace(p SearchPosition) {
 		fmt.Fprintf(t.Writer, "- %s\n", a)
 	}
 }
+
+// EventKind identifies the kind of an Event.
+type EventKind string
+
+const (
+	// EventGuessPushed is emitted when the search tentatively
+	// selects a Variable to satisfy a choice.
+	EventGuessPushed EventKind = "guess-pushed"
+	// EventGuessPopped is emitted when a guess is withdrawn.
+	EventGuessPopped EventKind = "guess-popped"
+	// EventBacktrack is emitted when the current guesses are found
+	// to be unsatisfiable, before the most recent one is withdrawn.
+	EventBacktrack EventKind = "backtrack"
+	// EventChoiceEnqueued is emitted when a choice between
+	// candidate Variables is scheduled to be made by the search.
+	EventChoiceEnqueued EventKind = "choice-enqueued"
+	// EventTestOutcome is emitted with the outcome of each test of
+	// the current guesses.
+	EventTestOutcome EventKind = "test-outcome"
+	// EventMinimizationStep is emitted with the outcome of each
+	// attempt to bound the cost or size of a solution.
+	EventMinimizationStep EventKind = "minimization-step"
+	// EventResult is emitted once, with the final outcome.
+	EventResult EventKind = "result"
+)
+
+// Event describes a single step taken while solving.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Time is the time at which the event occurred.
+	Time time.Time `json:"time"`
+	// Depth is the number of guesses on the search stack.
+	Depth int `json:"depth"`
+	// Variable identifies the guessed Variable of
+	// EventGuessPushed and EventGuessPopped events.
+	Variable Identifier `json:"variable,omitempty"`
+	// Candidates identifies the candidates of an
+	// EventChoiceEnqueued event, in order of preference.
+	Candidates []Identifier `json:"candidates,omitempty"`
+	// Outcome is one of "satisfiable", "unsatisfiable" or
+	// "unknown" for EventTestOutcome, EventMinimizationStep and
+	// EventResult events.
+	Outcome string `json:"outcome,omitempty"`
+	// Bound is the bound attempted by an EventMinimizationStep
+	// event, and nil for other events.
+	Bound *int `json:"bound,omitempty"`
+	// Variables identifies the guesses of an EventBacktrack event,
+	// or the selected Variables of a satisfiable EventResult
+	// event.
+	Variables []Identifier `json:"variables,omitempty"`
+	// Conflicts describes the applied constraints responsible for
+	// an EventBacktrack event or an unsatisfiable EventResult
+	// event.
+	Conflicts []Description `json:"conflicts,omitempty"`
+}
+
+// EventTracer may be implemented by a Tracer to be notified of every
+// Event during solving, in addition to the positions passed to Trace.
+type EventTracer interface {
+	Tracer
+	TraceEvent(e Event)
+}
+
+// JSONTracer is an EventTracer that writes each Event to Writer as a
+// single line of JSON. It is safe for concurrent use. Once writing an
+// Event fails, no further Events are written, and the error is
+// returned by Err.
+type JSONTracer struct {
+	Writer io.Writer
+	mu     sync.Mutex
+	enc    *json.Encoder
+	err    error
+}
+
+func (t *JSONTracer) Trace(_ SearchPosition) {
+}
+
+func (t *JSONTracer) TraceEvent(e Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	if t.enc == nil {
+		t.enc = json.NewEncoder(t.Writer)
+	}
+	// Encode terminates each value with a newline.
+	t.err = t.enc.Encode(e)
+}
+
+// Err returns the error that prevented an Event from being written,
+// if any.
+func (t *JSONTracer) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// emitter constructs and delivers Events to a Tracer, if it is an
+// EventTracer.
+type emitter struct {
+	tracer EventTracer
+}
+
+func newEmitter(t Tracer) emitter {
+	et, _ := t.(EventTracer)
+	return emitter{tracer: et}
+}
+
+// enabled returns true if emitted Events are delivered anywhere, so
+// that callers can avoid the cost of preparing them otherwise.
+func (e emitter) enabled() bool {
+	return e.tracer != nil
+}
+
+func (e emitter) emit(event Event) {
+	if e.tracer == nil {
+		return
+	}
+	event.Time = time.Now()
+	e.tracer.TraceEvent(event)
+}
+
+func outcomeString(outcome int) string {
+	switch outcome {
+	case satisfiable:
+		return "satisfiable"
+	case unsatisfiable:
+		return "unsatisfiable"
+	}
+	return "unknown"
+}
+
+func identifiersOf(vs []Variable) []Identifier {
+	ids := make([]Identifier, len(vs))
+	for i, v := range vs {
+		ids[i] = v.Identifier()
+	}
+	return ids
+}
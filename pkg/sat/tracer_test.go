@@ -0,0 +1,167 @@
+package sat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	s, err := NewSolver(WithTracer(&JSONTracer{Writer: &buf}), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x", Dependency("p", "q")),
+		variable("y"),
+		variable("b", Mandatory(), AtMost(1, "x", "p", "q")),
+		variable("p"),
+		variable("q"),
+	}))
+	assert.NoError(err)
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.Equal([]Identifier{"a", "y", "b"}, identifiers(installed))
+
+	var events []Event
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e Event
+		assert.NoError(json.Unmarshal(scanner.Bytes(), &e))
+		assert.False(e.Time.IsZero())
+		events = append(events, e)
+	}
+
+	// x is guessed first, found to be unsatisfiable, and replaced
+	// with y.
+	type step struct {
+		Kind     EventKind
+		Depth    int
+		Variable Identifier
+	}
+	var steps []step
+	for _, e := range events {
+		steps = append(steps, step{Kind: e.Kind, Depth: e.Depth, Variable: e.Variable})
+	}
+	assert.Subset(steps, []step{
+		{Kind: EventGuessPushed, Depth: 3, Variable: "x"},
+		{Kind: EventBacktrack, Depth: 3},
+		{Kind: EventGuessPopped, Depth: 2, Variable: "x"},
+		{Kind: EventGuessPushed, Depth: 3, Variable: "y"},
+	})
+	var backtrack Event
+	for _, e := range events {
+		if e.Kind == EventBacktrack {
+			backtrack = e
+			break
+		}
+	}
+	assert.Equal([]Identifier{"a", "b", "x"}, backtrack.Variables)
+	one := 1
+	assert.Contains(backtrack.Conflicts, Description{
+		Kind:       KindAtMost,
+		Subject:    "b",
+		References: []Identifier{"x", "p", "q"},
+		Max:        &one,
+		Message:    "b permits at most 1 of x, p, q",
+	})
+
+	// Minimization starts from a bound of zero, which must still
+	// be traced.
+	var bounds []int
+	for _, e := range events {
+		if e.Kind == EventMinimizationStep && assert.NotNil(e.Bound) {
+			bounds = append(bounds, *e.Bound)
+		}
+	}
+	assert.Equal([]int{0}, bounds)
+
+	result := events[len(events)-1]
+	assert.Equal(EventResult, result.Kind)
+	assert.Equal("satisfiable", result.Outcome)
+	assert.Nil(result.Bound)
+	assert.Equal([]Identifier{"a", "y", "b"}, result.Variables)
+}
+
+func TestJSONTracerScoredCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	s, err := NewSolver(WithTracer(&JSONTracer{Writer: &buf}), WithSearchStrategy(ScoredBy(func(_ Variable, candidate Variable) int {
+		if candidate.Identifier() == "y" {
+			return 1
+		}
+		return 0
+	})), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x"),
+		variable("y"),
+	}))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+	assert.NoError(err)
+
+	var candidates [][]Identifier
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e Event
+		assert.NoError(json.Unmarshal(scanner.Bytes(), &e))
+		if e.Kind == EventChoiceEnqueued {
+			candidates = append(candidates, e.Candidates)
+		}
+	}
+	assert.Contains(candidates, []Identifier{"y", "x"})
+}
+
+func TestJSONTracerNotSatisfiable(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	s, err := NewSolver(WithTracer(&JSONTracer{Writer: &buf}), WithInput([]Variable{
+		variable("a", Mandatory(), Prohibited()),
+	}))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+	assert.Error(err)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var result Event
+	assert.NoError(json.Unmarshal(lines[len(lines)-1], &result))
+	assert.Equal(EventResult, result.Kind)
+	assert.Equal("unsatisfiable", result.Outcome)
+	assert.ElementsMatch([]Description{
+		{Kind: KindMandatory, Subject: "a", Message: "a is mandatory"},
+		{Kind: KindProhibited, Subject: "a", Message: "a is prohibited"},
+	}, result.Conflicts)
+}
+
+// failingWriter fails every write.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("write failed")
+}
+
+func TestJSONTracerErr(t *testing.T) {
+	assert := assert.New(t)
+
+	var w failingWriter
+	tracer := JSONTracer{Writer: &w}
+	s, err := NewSolver(WithTracer(&tracer), WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("b")),
+		variable("b"),
+	}))
+	assert.NoError(err)
+	_, err = s.Solve(context.TODO())
+	assert.NoError(err)
+	assert.EqualError(tracer.Err(), "write failed")
+	assert.Equal(1, w.writes)
+}
@@ -0,0 +1,197 @@
+package sat
+
+import (
+	"fmt"
+)
+
+// DanglingReference is reported when a constraint refers to an
+// Identifier that does not identify any Variable in the input.
+type DanglingReference struct {
+	AppliedConstraint
+	Reference Identifier
+}
+
+func (e DanglingReference) Error() string {
+	return fmt.Sprintf("%s: variable %q referenced but not provided", e.AppliedConstraint, e.Reference)
+}
+
+// SelfDependency is reported when a Dependency lists the Variable it
+// is applied to among its candidates, which makes it trivially
+// satisfied.
+type SelfDependency struct {
+	AppliedConstraint
+}
+
+func (e SelfDependency) Error() string {
+	return fmt.Sprintf("%s: variable %q depends on itself", e.AppliedConstraint, e.Variable.Identifier())
+}
+
+// SelfConflict is reported when a Conflict names the Variable it is
+// applied to, which makes that Variable impossible to select.
+type SelfConflict struct {
+	AppliedConstraint
+}
+
+func (e SelfConflict) Error() string {
+	return fmt.Sprintf("%s: variable %q conflicts with itself", e.AppliedConstraint, e.Variable.Identifier())
+}
+
+// EmptyCardinality is reported when a cardinality constraint, such
+// as AtMost, is not given any Identifiers to count.
+type EmptyCardinality struct {
+	AppliedConstraint
+}
+
+func (e EmptyCardinality) Error() string {
+	return fmt.Sprintf("%s: no variables to count", e.AppliedConstraint)
+}
+
+// NegativeBound is reported when a cardinality constraint is given a
+// negative bound.
+type NegativeBound struct {
+	AppliedConstraint
+	Bound int
+}
+
+func (e NegativeBound) Error() string {
+	return fmt.Sprintf("%s: negative bound %d", e.AppliedConstraint, e.Bound)
+}
+
+// Validate checks the provided Variables for problems that are likely
+// to be mistakes, without encoding them for a SAT solver. It returns
+// a DuplicateIdentifier error for each Identifier shared by several
+// Variables, followed by a DanglingReference, SelfDependency,
+// SelfConflict, EmptyCardinality or NegativeBound error for each
+// problem found with an applied constraint, in input order. The
+// Constraints of composed Constraints, such as those returned by Not
+// or All, are checked as well. Constraints not provided by this
+// package can only be checked for dangling references among the
+// Identifiers returned by their Order method.
+//
+// Only duplicate Identifiers and, unless WithDanglingReferences is
+// used, dangling references prevent NewSolver from succeeding; the
+// remaining problems produce well-defined, if probably unintended,
+// results.
+func Validate(vars []Variable) []error {
+	var errs []error
+	known := make(map[Identifier]struct{}, len(vars))
+	for _, v := range vars {
+		id := v.Identifier()
+		if _, ok := known[id]; ok {
+			errs = append(errs, DuplicateIdentifier(id))
+			continue
+		}
+		known[id] = struct{}{}
+	}
+	for _, v := range vars {
+		for _, c := range v.Constraints() {
+			a := AppliedConstraint{Variable: v, Constraint: c}
+			for _, id := range references(c) {
+				if _, ok := known[id]; !ok {
+					errs = append(errs, DanglingReference{AppliedConstraint: a, Reference: id})
+				}
+			}
+			errs = validate(errs, a, c)
+		}
+	}
+	return errs
+}
+
+// validate appends the problems, other than dangling references,
+// found with c, which is either the Constraint of a or one of its
+// operands, to errs.
+func validate(errs []error, a AppliedConstraint, c Constraint) []error {
+	cardinality := func(ids []Identifier, bounds ...int) {
+		if len(ids) == 0 {
+			errs = append(errs, EmptyCardinality{AppliedConstraint: a})
+		}
+		for _, n := range bounds {
+			if n < 0 {
+				errs = append(errs, NegativeBound{AppliedConstraint: a, Bound: n})
+			}
+		}
+	}
+
+	switch c := c.(type) {
+	case dependency:
+		for _, id := range c {
+			if id == a.Variable.Identifier() {
+				errs = append(errs, SelfDependency{AppliedConstraint: a})
+				break
+			}
+		}
+	case conflict:
+		if Identifier(c) == a.Variable.Identifier() {
+			errs = append(errs, SelfConflict{AppliedConstraint: a})
+		}
+	case leq:
+		cardinality(c.ids, c.n)
+	case geq:
+		cardinality(c.ids, c.n)
+	case between:
+		cardinality(c.ids, c.lo, c.hi)
+	case weightedSum:
+		if len(c.terms) == 0 {
+			errs = append(errs, EmptyCardinality{AppliedConstraint: a})
+		}
+	case not:
+		errs = validate(errs, a, c.operand)
+	case allOf:
+		for _, operand := range c {
+			errs = validate(errs, a, operand)
+		}
+	case anyOf:
+		for _, operand := range c {
+			errs = validate(errs, a, operand)
+		}
+	case implies:
+		errs = validate(errs, a, c.antecedent)
+		errs = validate(errs, a, c.consequent)
+	}
+	return errs
+}
+
+// references returns the Identifiers, other than that of its subject,
+// that a Constraint refers to. For Constraints not provided by this
+// package, these are assumed to be the Identifiers returned by Order.
+func references(c Constraint) []Identifier {
+	switch c := c.(type) {
+	case mandatory, prohibited, prefer, penalty, zeroConstraint:
+		return nil
+	case dependency:
+		return c
+	case conflict:
+		return []Identifier{Identifier(c)}
+	case leq:
+		return c.ids
+	case geq:
+		return c.ids
+	case between:
+		return c.ids
+	case weightedSum:
+		ids := make([]Identifier, len(c.terms))
+		for i, each := range c.terms {
+			ids[i] = each.Identifier
+		}
+		return ids
+	case selected:
+		return []Identifier{Identifier(c)}
+	case not:
+		return references(c.operand)
+	case allOf:
+		var ids []Identifier
+		for _, operand := range c {
+			ids = append(ids, references(operand)...)
+		}
+		return ids
+	case anyOf:
+		var ids []Identifier
+		for _, operand := range c {
+			ids = append(ids, references(operand)...)
+		}
+		return ids
+	case implies:
+		return append(references(c.antecedent), references(c.consequent)...)
+	}
+	return c.Order()
+}
@@ -0,0 +1,129 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	a := func(v Variable, i int) AppliedConstraint {
+		return AppliedConstraint{Variable: v, Constraint: v.Constraints()[i]}
+	}
+
+	var (
+		dangling      = variable("a", Dependency("x", "missing"))
+		composed      = variable("a", Not(All(Selected("x"), Selected("missing"))))
+		selfDependent = variable("a", Dependency("b", "a"))
+		selfConflict  = variable("a", Conflict("a"))
+		empty         = variable("a", AtMost(1))
+		negative      = variable("a", AtLeast(-1, "b"), Between(-2, 1, "b"))
+	)
+
+	for _, tt := range []struct {
+		Name      string
+		Variables []Variable
+		Errors    []error
+	}{
+		{
+			Name: "valid",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b"), Conflict("c"), AtMost(1, "b", "c"), Prefer(-1)),
+				variable("b"),
+				variable("c"),
+			},
+		},
+		{
+			Name: "duplicate identifier",
+			Variables: []Variable{
+				variable("a"),
+				variable("b"),
+				variable("a"),
+			},
+			Errors: []error{DuplicateIdentifier("a")},
+		},
+		{
+			Name:      "dangling reference",
+			Variables: []Variable{dangling, variable("x")},
+			Errors: []error{
+				DanglingReference{AppliedConstraint: a(dangling, 0), Reference: "missing"},
+			},
+		},
+		{
+			Name:      "dangling reference in operand",
+			Variables: []Variable{composed, variable("x")},
+			Errors: []error{
+				DanglingReference{AppliedConstraint: a(composed, 0), Reference: "missing"},
+			},
+		},
+		{
+			Name:      "self dependency",
+			Variables: []Variable{selfDependent, variable("b")},
+			Errors:    []error{SelfDependency{AppliedConstraint: a(selfDependent, 0)}},
+		},
+		{
+			Name:      "self conflict",
+			Variables: []Variable{selfConflict},
+			Errors:    []error{SelfConflict{AppliedConstraint: a(selfConflict, 0)}},
+		},
+		{
+			Name:      "empty cardinality",
+			Variables: []Variable{empty},
+			Errors:    []error{EmptyCardinality{AppliedConstraint: a(empty, 0)}},
+		},
+		{
+			Name:      "negative bounds",
+			Variables: []Variable{negative, variable("b")},
+			Errors: []error{
+				NegativeBound{AppliedConstraint: a(negative, 0), Bound: -1},
+				NegativeBound{AppliedConstraint: a(negative, 1), Bound: -2},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Errors, Validate(tt.Variables))
+		})
+	}
+}
+
+func TestValidateErrorStrings(t *testing.T) {
+	errs := Validate([]Variable{
+		variable("a", Dependency("a", "x"), Conflict("a"), AtMost(-1)),
+	})
+	var s []string
+	for _, err := range errs {
+		s = append(s, err.Error())
+	}
+	assert.Equal(t, []string{
+		`a requires at least one of a, x: variable "x" referenced but not provided`,
+		`a requires at least one of a, x: variable "a" depends on itself`,
+		`a conflicts with a: variable "a" conflicts with itself`,
+		`a permits at most -1 of : no variables to count`,
+		`a permits at most -1 of : negative bound -1`,
+	}, s)
+}
+
+func TestNewSolverDanglingReference(t *testing.T) {
+	input := []Variable{
+		variable("a", Mandatory(), Dependency("missing")),
+	}
+
+	_, err := NewSolver(WithInput(input))
+	var dangling DanglingReference
+	if assert.True(t, errors.As(err, &dangling)) {
+		assert.Equal(t, Identifier("a"), dangling.Variable.Identifier())
+		assert.Equal(t, Identifier("missing"), dangling.Reference)
+	}
+
+	s, err := NewSolver(WithInput(input), WithDanglingReferences())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = s.Solve(context.Background())
+	assert.ElementsMatch(t, NotSatisfiable{
+		{Variable: input[0], Constraint: Mandatory()},
+		{Variable: input[0], Constraint: Dependency("missing")},
+	}, err)
+}
@@ -0,0 +1,107 @@
+package sat
+
+import (
+	"fmt"
+)
+
+// Evaluator may be implemented by a Constraint to allow Verify to
+// check it directly, without encoding it for a SAT solver. Evaluate
+// reports whether the Constraint, applied to subject, holds for the
+// selection described by selected. All Constraints provided by this
+// package implement Evaluator.
+type Evaluator interface {
+	Evaluate(subject Identifier, selected func(Identifier) bool) bool
+}
+
+// evaluator is implemented by Constraints composed of operands that
+// might not implement Evaluator.
+type evaluator interface {
+	evaluate(subject Identifier, selected func(Identifier) bool) (value bool, ok bool)
+}
+
+// evaluate reports whether a Constraint applied to subject holds for
+// the selection described by selected, and whether that could be
+// determined at all.
+func evaluate(c Constraint, subject Identifier, selected func(Identifier) bool) (bool, bool) {
+	switch c := c.(type) {
+	case evaluator:
+		return c.evaluate(subject, selected)
+	case Evaluator:
+		return c.Evaluate(subject, selected), true
+	}
+	return false, false
+}
+
+// count returns the number of the given Identifiers that are
+// selected, counting duplicates each time they appear.
+func count(ids []Identifier, selected func(Identifier) bool) int {
+	var n int
+	for _, id := range ids {
+		if selected(id) {
+			n++
+		}
+	}
+	return n
+}
+
+// Violation describes an applied constraint that does not hold for a
+// selection passed to Verify.
+type Violation struct {
+	AppliedConstraint
+	// Unverifiable is true if the constraint, or one of its
+	// operands, does not implement Evaluator, so that it could
+	// not be checked at all.
+	Unverifiable bool
+}
+
+// String implements fmt.Stringer and returns a human-readable message
+// representing the receiver.
+func (v Violation) String() string {
+	if v.Unverifiable {
+		return fmt.Sprintf("unable to verify that %s", v.AppliedConstraint)
+	}
+	return fmt.Sprintf("violated: %s", v.AppliedConstraint)
+}
+
+// Verify checks every constraint of the provided Variables against a
+// selection of them, returning a Violation for each that does not
+// hold, in input order. Selected Variables are matched by Identifier,
+// and any Identifier outside of vars is treated as unselected.
+// SoftConstraints are never reported, since violating them only
+// affects the cost of a solution. The result is empty if selected is
+// a valid solution, although not necessarily one that Solve would
+// return.
+func Verify(vars []Variable, selected []Variable) []Violation {
+	known := make(map[Identifier]struct{}, len(vars))
+	for _, v := range vars {
+		known[v.Identifier()] = struct{}{}
+	}
+	set := make(map[Identifier]struct{}, len(selected))
+	for _, v := range selected {
+		if _, ok := known[v.Identifier()]; ok {
+			set[v.Identifier()] = struct{}{}
+		}
+	}
+	isSelected := func(id Identifier) bool {
+		_, ok := set[id]
+		return ok
+	}
+
+	var violations []Violation
+	for _, v := range vars {
+		for _, c := range v.Constraints() {
+			if _, ok := c.(SoftConstraint); ok {
+				continue
+			}
+			holds, ok := evaluate(c, v.Identifier(), isSelected)
+			if ok && holds {
+				continue
+			}
+			violations = append(violations, Violation{
+				AppliedConstraint: AppliedConstraint{Variable: v, Constraint: c},
+				Unverifiable:      !ok,
+			})
+		}
+	}
+	return violations
+}
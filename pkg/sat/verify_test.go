@@ -0,0 +1,194 @@
+package sat
+
+import (
+	"testing"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+// opaque is a Constraint that does not implement Evaluator.
+type opaque struct{}
+
+func (opaque) String(subject Identifier) string {
+	return string(subject) + " is opaque"
+}
+
+func (opaque) Apply(c *logic.C, lm *LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(subject)
+}
+
+func (opaque) Order() []Identifier {
+	return nil
+}
+
+func (opaque) Anchor() bool {
+	return false
+}
+
+func TestVerify(t *testing.T) {
+	type violation struct {
+		Identifier   Identifier
+		Unverifiable bool
+	}
+
+	for _, tt := range []struct {
+		Name       string
+		Variables  []Variable
+		Selected   []Identifier
+		Violations []violation
+	}{
+		{
+			Name: "no constraints",
+			Variables: []Variable{
+				variable("a"),
+			},
+			Selected: []Identifier{"a"},
+		},
+		{
+			Name: "mandatory not selected",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b"),
+			},
+			Selected:   []Identifier{"b"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "prohibited selected",
+			Variables: []Variable{
+				variable("a", Prohibited()),
+			},
+			Selected:   []Identifier{"a"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "dependency satisfied",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Selected: []Identifier{"a", "y"},
+		},
+		{
+			Name: "dependency unsatisfied",
+			Variables: []Variable{
+				variable("a", Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Selected:   []Identifier{"a"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "dependency of unselected variable",
+			Variables: []Variable{
+				variable("a", Dependency("x")),
+				variable("x"),
+			},
+		},
+		{
+			Name: "dependency on missing variable",
+			Variables: []Variable{
+				variable("a", Dependency("x")),
+			},
+			Selected:   []Identifier{"a", "x"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "conflict",
+			Variables: []Variable{
+				variable("a", Conflict("b")),
+				variable("b"),
+			},
+			Selected:   []Identifier{"a", "b"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "at most violated by unselected subject",
+			Variables: []Variable{
+				variable("a", AtMost(1, "x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Selected:   []Identifier{"x", "y"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "at least and between",
+			Variables: []Variable{
+				variable("a", AtLeast(1, "x", "y"), Between(1, 1, "x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Selected:   []Identifier{"x", "y"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "soft constraints are ignored",
+			Variables: []Variable{
+				variable("a", Prefer(1)),
+				variable("b", Penalty(1)),
+			},
+			Selected: []Identifier{"b"},
+		},
+		{
+			Name: "combinators",
+			Variables: []Variable{
+				variable("a", Implies(Selected("x"), Not(Selected("y"))), Any(Selected("x"), Selected("y"))),
+				variable("x"),
+				variable("y"),
+			},
+			Selected:   []Identifier{"x", "y"},
+			Violations: []violation{{Identifier: "a"}},
+		},
+		{
+			Name: "custom constraint without evaluator",
+			Variables: []Variable{
+				variable("a", opaque{}),
+			},
+			Violations: []violation{{Identifier: "a", Unverifiable: true}},
+		},
+		{
+			Name: "combinator satisfied despite opaque operand",
+			Variables: []Variable{
+				variable("a", Any(opaque{}, Selected("x"))),
+				variable("x"),
+			},
+			Selected: []Identifier{"x"},
+		},
+		{
+			Name: "combinator with opaque operand",
+			Variables: []Variable{
+				variable("a", All(opaque{}, Selected("x"))),
+				variable("x"),
+			},
+			Selected:   []Identifier{"x"},
+			Violations: []violation{{Identifier: "a", Unverifiable: true}},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var selected []Variable
+			for _, id := range tt.Selected {
+				selected = append(selected, variable(id))
+			}
+			var actual []violation
+			for _, v := range Verify(tt.Variables, selected) {
+				actual = append(actual, violation{Identifier: v.Variable.Identifier(), Unverifiable: v.Unverifiable})
+			}
+			assert.Equal(t, tt.Violations, actual)
+		})
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	assert := assert.New(t)
+
+	vs := Verify([]Variable{variable("a", Mandatory()), variable("b", opaque{})}, nil)
+	if assert.Len(vs, 2) {
+		assert.Equal("violated: a is mandatory", vs[0].String())
+		assert.Equal("unable to verify that b is opaque", vs[1].String())
+	}
+}